@@ -0,0 +1,59 @@
+package visualisations
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Accessibility controls the metadata a chart carries for screen
+// readers. the zero value describes the chart with generated summaries
+type Accessibility struct {
+	// Title and Description replace the generated title and summary of
+	// the chart
+	Title       string
+	Description string
+	// Disabled leaves out every title and description, for charts that
+	// are described by the page around them
+	Disabled bool
+}
+
+// Describe returns `title` and `desc` unless they are overridden, or
+// nothing when the metadata is disabled
+func (a Accessibility) Describe(title, desc string) (string, string) {
+	if a.Disabled {
+		return "", ""
+	}
+	if a.Title != "" {
+		title = a.Title
+	}
+	if a.Description != "" {
+		desc = a.Description
+	}
+	return title, desc
+}
+
+// Percent formats a proportion between 0 and 1 as a whole percentage
+func Percent(p float64) string {
+	return fmt.Sprintf("%.0f%%", p*100)
+}
+
+// List joins `items` into an english list such as "a, b and c"
+func List(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// describedID generates an id for the title and description of a
+// drawing. it depends only on their text, so output is reproducible
+// and different charts on one page are unlikely to clash
+func describedID(title, desc string) string {
+	h := fnv.New32a()
+	h.Write([]byte(title + "\x00" + desc))
+	return fmt.Sprintf("vis-%08x", h.Sum32())
+}
@@ -0,0 +1,111 @@
+package visualisations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAccessibilityDescribe(t *testing.T) {
+	for _, testcase := range []struct {
+		name          string
+		accessibility Accessibility
+		title, desc   string
+	}{
+		{
+			name:  "generated",
+			title: "Gauge",
+			desc:  "Gauge at 50%",
+		},
+		{
+			name:          "overridden",
+			accessibility: Accessibility{Title: "CPU", Description: "Half used"},
+			title:         "CPU",
+			desc:          "Half used",
+		},
+		{
+			name:          "disabled",
+			accessibility: Accessibility{Title: "CPU", Disabled: true},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			title, desc := testcase.accessibility.Describe("Gauge", "Gauge at 50%")
+			if title != testcase.title || desc != testcase.desc {
+				t.Errorf("got %q, %q, want %q, %q", title, desc, testcase.title, testcase.desc)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	for _, testcase := range []struct {
+		items []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b"}, "a and b"},
+		{[]string{"a", "b", "c"}, "a, b and c"},
+	} {
+		if got := List(testcase.items); got != testcase.want {
+			t.Errorf("List(%q) = %q, want %q", testcase.items, got, testcase.want)
+		}
+	}
+}
+
+type describedSquare struct {
+	square
+	title, desc string
+}
+
+func (s describedSquare) Describe() (string, string) {
+	return s.title, s.desc
+}
+
+func TestSVGDescribe(t *testing.T) {
+	for _, testcase := range []struct {
+		name        string
+		title, desc string
+		want        []string
+	}{
+		{
+			name:  "title and description",
+			title: "Square",
+			desc:  "A <red> square",
+			want: []string{
+				`role="img"`,
+				`aria-labelledby="vis-50e348b1-title vis-50e348b1-desc"`,
+				`<title id="vis-50e348b1-title">Square</title>`,
+				`<desc id="vis-50e348b1-desc">A &lt;red&gt; square</desc>`,
+			},
+		},
+		{
+			name:  "title only",
+			title: "Square",
+			want: []string{
+				`aria-labelledby="vis-31480492-title"`,
+				`<title id="vis-31480492-title">Square</title>`,
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			d := describedSquare{square{Size: 10}, testcase.title, testcase.desc}
+			if err := DrawTo(NewSVG(builder), d); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range testcase.want {
+				if !strings.Contains(builder.String(), want) {
+					t.Errorf("output is missing %s:\n%s", want, builder.String())
+				}
+			}
+		})
+	}
+
+	builder := &strings.Builder{}
+	if err := DrawTo(NewSVG(builder), describedSquare{square: square{Size: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(builder.String(), "role=") {
+		t.Errorf("undescribed drawing has a role:\n%s", builder.String())
+	}
+}
@@ -0,0 +1,277 @@
+// Package axis draws value axes for scales, either along a straight
+// line or along the radius of a circular chart
+package axis
+
+import (
+	"math"
+	"strconv"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/scale"
+)
+
+type Orientation int
+
+const (
+	// Bottom draws ticks and labels below a horizontal line
+	Bottom Orientation = iota
+	// Top draws ticks and labels above a horizontal line
+	Top
+	// Left draws ticks and labels to the left of a vertical line
+	Left
+	// Right draws ticks and labels to the right of a vertical line
+	Right
+)
+
+var (
+	defaultLineStyle = visual.Style{
+		Fill:        "none",
+		Stroke:      "black",
+		StrokeWidth: visual.Float(1),
+		Class:       "axis-line",
+	}
+	defaultLabelStyle = visual.Style{
+		Fill:       "black",
+		FontFamily: "sans-serif",
+		FontSize:   visual.Int(10),
+		Class:      "axis-label",
+	}
+	defaultRingStyle = visual.Style{
+		Fill:          "none",
+		Stroke:        "black",
+		StrokeOpacity: visual.Float(0.15),
+		Class:         "axis-ring",
+	}
+)
+
+const (
+	defaultTickCount   = 5
+	defaultTickSize    = 6
+	defaultTickPadding = 3
+)
+
+// TickOptions are the options shared by every kind of axis
+type TickOptions struct {
+	// TickCount is the rough number of ticks to generate from the scale
+	TickCount int
+	// TickValues overrides the generated ticks
+	TickValues []float64
+	// Format turns a tick value into its label, defaults to a fixed
+	// number of decimals suited to the spacing of the ticks
+	Format func(float64) string
+	// TickSize is the length of the tick marks
+	TickSize float64
+	// TickPadding is the gap between a tick mark and its label
+	TickPadding float64
+	LineStyle   visual.Style
+	LabelStyle  visual.Style
+}
+
+// validate records the tick options that would stop an axis from being
+// drawn correctly in `v`
+func (t TickOptions) validate(v *visual.ValidationError) {
+	v.NonNegative("TickCount", float64(t.TickCount))
+	v.NonNegative("TickPadding", t.TickPadding)
+}
+
+func (t TickOptions) values(s scale.Continuous) []float64 {
+	if t.TickValues != nil {
+		return t.TickValues
+	}
+	count := t.TickCount
+	if count == 0 {
+		count = defaultTickCount
+	}
+	if ticker, ok := s.(scale.Ticker); ok {
+		return ticker.Ticks(count)
+	}
+	return nil
+}
+
+func (t TickOptions) format(values []float64) func(float64) string {
+	if t.Format != nil {
+		return t.Format
+	}
+	return DefaultFormat(values)
+}
+
+func (t TickOptions) size() (size, padding float64) {
+	size, padding = t.TickSize, t.TickPadding
+	if size == 0 {
+		size = defaultTickSize
+	}
+	if padding == 0 {
+		padding = defaultTickPadding
+	}
+	return size, padding
+}
+
+// DefaultFormat returns a formatter that shows just enough decimals to
+// tell evenly spaced `values` apart
+func DefaultFormat(values []float64) func(float64) string {
+	decimals := 0
+	if len(values) > 1 {
+		step := math.Abs(values[1] - values[0])
+		if step > 0 && step < 1 {
+			decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+		}
+	}
+	return func(v float64) string {
+		return strconv.FormatFloat(v, 'f', decimals, 64)
+	}
+}
+
+// Linear is a straight axis. its scale maps values onto a distance in
+// pixels along the axis from where it is drawn
+type Linear struct {
+	TickOptions
+	Scale  scale.Continuous
+	Orient Orientation
+	// Extent is the span of the axis line in pixels, it defaults to the
+	// span of the ticks
+	Extent [2]float64
+}
+
+// Time creates a linear axis for a time scale, labelling ticks on
+// natural boundaries with the time `layout`
+func Time(s scale.Time, count int, layout string) Linear {
+	ticks := s.Ticks(count)
+	values := make([]float64, len(ticks))
+	for i, t := range ticks {
+		values[i] = scale.Unix(t)
+	}
+	loc := s.Domain[0].Location()
+	a := Linear{Scale: s.Seconds(), Extent: s.Range}
+	a.TickValues = values
+	a.Format = func(v float64) string {
+		return scale.FromUnix(v).In(loc).Format(layout)
+	}
+	return a
+}
+
+func (a Linear) horizontal() bool {
+	return a.Orient == Bottom || a.Orient == Top
+}
+
+// Draw draws the axis with its origin at `x`, `y`
+func (a Linear) Draw(canvas visual.Canvas, x, y float64) {
+	values := a.values(a.Scale)
+	format := a.format(values)
+	size, padding := a.size()
+	lineStyle := a.LineStyle.Merge(defaultLineStyle)
+	labelStyle := a.LabelStyle.Merge(defaultLabelStyle)
+	// direction of the ticks away from the line
+	dir := 1.0
+	if a.Orient == Top || a.Orient == Left {
+		dir = -1
+	}
+	switch a.Orient {
+	case Bottom:
+		labelStyle = labelStyle.Merge(visual.Style{
+			TextAnchor: "middle", DominantBaseline: "hanging"})
+	case Top:
+		labelStyle = labelStyle.Merge(visual.Style{
+			TextAnchor: "middle", DominantBaseline: "auto"})
+	case Left:
+		labelStyle = labelStyle.Merge(visual.Style{
+			TextAnchor: "end", DominantBaseline: "central"})
+	case Right:
+		labelStyle = labelStyle.Merge(visual.Style{
+			TextAnchor: "start", DominantBaseline: "central"})
+	}
+	// point returns coordinates `along` the axis and `across` it
+	point := func(along, across float64) (float64, float64) {
+		if a.horizontal() {
+			return along, across
+		}
+		return across, along
+	}
+	canvas.Translate(x, y)
+	defer canvas.EndGroup()
+	extent := a.Extent
+	if extent == [2]float64{} && len(values) > 0 {
+		extent = [2]float64{
+			a.Scale.Map(values[0]),
+			a.Scale.Map(values[len(values)-1]),
+		}
+	}
+	x1, y1 := point(extent[0], 0)
+	x2, y2 := point(extent[1], 0)
+	canvas.Line(x1, y1, x2, y2, lineStyle)
+	for _, v := range values {
+		pos := a.Scale.Map(v)
+		x1, y1 := point(pos, 0)
+		x2, y2 := point(pos, dir*size)
+		canvas.Line(x1, y1, x2, y2, lineStyle)
+		lx, ly := point(pos, dir*(size+padding))
+		canvas.Text(lx, ly, format(v), labelStyle)
+	}
+}
+
+// Radial is an axis along the radius of a circular chart. its scale
+// maps values onto a proportion between 0 and 1 of the distance from
+// Inner to Outer
+type Radial struct {
+	TickOptions
+	Scale        scale.Continuous
+	Inner, Outer float64
+	// Angle is the direction of the axis in degrees, clockwise from
+	// pointing right
+	Angle float64
+	// Rings draws a circle through every tick
+	Rings     bool
+	RingStyle visual.Style
+}
+
+// Validate reports every option that would stop the axis from being
+// drawn correctly
+func (a Radial) Validate() error {
+	v := &visual.ValidationError{}
+	a.TickOptions.validate(v)
+	v.NonNegative("Inner", a.Inner)
+	v.Check(a.Outer >= a.Inner, "Outer", a.Outer, "must be at least Inner")
+	return v.Err()
+}
+
+// Radius returns the distance from the centre of the value `v`
+func (a Radial) Radius(v float64) float64 {
+	return visual.ScaleRange(a.Scale.Map(v), 0, 1, a.Inner, a.Outer)
+}
+
+// Draw draws the axis around the centre `cx`, `cy`
+func (a Radial) Draw(canvas visual.Canvas, cx, cy float64) {
+	values := a.values(a.Scale)
+	format := a.format(values)
+	size, padding := a.size()
+	lineStyle := a.LineStyle.Merge(defaultLineStyle)
+	// ticks are drawn perpendicular to the axis, on its clockwise side,
+	// with labels anchored to whichever side faces the tick
+	dx, dy := visual.PointOnCircum(0, 0, 1, a.Angle+90)
+	anchor := "middle"
+	if dx > 0.3 {
+		anchor = "start"
+	} else if dx < -0.3 {
+		anchor = "end"
+	}
+	labelStyle := a.LabelStyle.Merge(defaultLabelStyle).Merge(visual.Style{
+		TextAnchor:       anchor,
+		DominantBaseline: "central",
+	})
+	ringStyle := a.RingStyle.Merge(defaultRingStyle)
+	canvas.Group("")
+	defer canvas.EndGroup()
+	if a.Rings {
+		for _, v := range values {
+			canvas.Circle(cx, cy, a.Radius(v), ringStyle)
+		}
+	}
+	x1, y1 := visual.PointOnCircum(cx, cy, a.Inner, a.Angle)
+	x2, y2 := visual.PointOnCircum(cx, cy, a.Outer, a.Angle)
+	canvas.Line(x1, y1, x2, y2, lineStyle)
+	for _, v := range values {
+		px, py := visual.PointOnCircum(cx, cy, a.Radius(v), a.Angle)
+		canvas.Line(px, py, px+dx*size, py+dy*size, lineStyle)
+		offset := size + padding
+		canvas.Text(px+dx*offset, py+dy*offset, format(v), labelStyle)
+	}
+}
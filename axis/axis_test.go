@@ -0,0 +1,85 @@
+package axis
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/diff"
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/scale"
+	"github.com/osraige/visualisations/visualtest"
+)
+
+var (
+	update = flag.Bool("update", false, "update the golden files of this test")
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	os.Exit(m.Run())
+}
+
+func TestDraw(t *testing.T) {
+	start := time.Date(2020, 3, 1, 9, 20, 0, 0, time.UTC)
+	for _, testcase := range []struct {
+		golden string
+		draw   func(visual.Canvas)
+	}{
+		{
+			golden: "bottom",
+			draw: func(canvas visual.Canvas) {
+				Linear{
+					Scale: scale.Linear{
+						Domain: [2]float64{0, 1},
+						Range:  [2]float64{0, 180},
+					},
+					Extent: [2]float64{0, 180},
+				}.Draw(canvas, 10, 20)
+			},
+		}, {
+			golden: "left-formatted",
+			draw: func(canvas visual.Canvas) {
+				a := Linear{
+					Scale: scale.Linear{
+						Domain: [2]float64{0, 2000},
+						Range:  [2]float64{180, 0},
+					}.Nice(4),
+					Orient: Left,
+				}
+				a.TickCount = 4
+				a.Format = func(v float64) string {
+					return strconv.FormatFloat(v/1000, 'g', -1, 64) + "k"
+				}
+				a.LabelStyle = visual.Style{Fill: "grey"}
+				a.Draw(canvas, 40, 10)
+			},
+		}, {
+			golden: "time",
+			draw: func(canvas visual.Canvas) {
+				Time(scale.Time{
+					Domain: [2]time.Time{start, start.Add(5 * time.Hour)},
+					Range:  [2]float64{0, 180},
+				}, 5, "15:04").Draw(canvas, 10, 20)
+			},
+		},
+	} {
+		t.Run(testcase.golden, func(t *testing.T) {
+			builder := &strings.Builder{}
+			canvas := visual.NewSVG(builder)
+			canvas.Start(200, 200)
+			testcase.draw(canvas)
+			if err := canvas.End(); err != nil {
+				t.Fatal(err)
+			}
+			got := builder.String()
+			want := visualtest.GoldenValue(t, testcase.golden, got, *update)
+			if got != want {
+				t.Errorf("mismatched output:\n%s", diff.Diff(want, got))
+			}
+		})
+	}
+}
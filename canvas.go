@@ -0,0 +1,122 @@
+package visualisations
+
+// Canvas is a surface the charts draw onto. it covers only the
+// primitives the charts use so that other outputs can be added by
+// implementing it, see SVG for the default
+type Canvas interface {
+	// Start begins a drawing `width` by `height` pixels
+	Start(width, height float64)
+	// End finishes the drawing, returning the first error hit while
+	// writing it out
+	End() error
+
+	// Group starts a group of elements, `id` may be empty
+	Group(id string)
+	// Translate starts a group with its origin moved to `x`, `y`
+	Translate(x, y float64)
+	// TranslateRotate starts a group with its origin moved to `x`, `y`
+	// and then rotated clockwise by `degrees`
+	TranslateRotate(x, y, degrees float64)
+	// EndGroup ends the most recently started group
+	EndGroup()
+	// Title describes the current group, such as with a tooltip
+	Title(text string)
+
+	Line(x1, y1, x2, y2 float64, style Style)
+	// Polyline joins up the points `xs`, `ys`. it is filled as though
+	// it was closed when the style has a fill
+	Polyline(xs, ys []float64, style Style)
+	// Path draws arcs, beziers and lines built with Path
+	Path(p *Path, style Style)
+	Circle(cx, cy, r float64, style Style)
+	Rect(x, y, width, height float64, style Style)
+	// Text draws `text` at `x`, `y`, aligned using the text anchor and
+	// dominant baseline of the style
+	Text(x, y float64, text string, style Style)
+
+	// Animate fades the `attribute` of the group with the id `target`
+	// from `from` to `to` over `duration` seconds, `repeat` times or
+	// forever when zero. canvases that can't animate draw the group as
+	// it is at the end of the animation
+	Animate(target, attribute string, from, to, duration float64, repeat int)
+}
+
+// Static can be embedded in canvases that can't animate, so that
+// Animate does nothing and every group is drawn as it is at the end of
+// its animation
+type Static struct{}
+
+func (Static) Animate(target, attribute string, from, to, duration float64, repeat int) {}
+
+// DrawLine draws a line onto `c` with Path, for canvases that draw
+// every shape as a path. the line is never filled, as in svg
+func DrawLine(c Canvas, x1, y1, x2, y2 float64, style Style) {
+	c.Path(NewPath().MoveTo(x1, y1).LineTo(x2, y2), style.Override(Style{Fill: "none"}))
+}
+
+// Drawer is a visualisation that can draw itself onto any Canvas
+type Drawer interface {
+	Validate() error
+	PreferredSize() (width, height float64)
+	// Draw draws the visualisation onto a started canvas. the options
+	// must be valid
+	Draw(c Canvas)
+}
+
+// Describer is a visualisation that can summarise itself for screen
+// readers
+type Describer interface {
+	// Describe returns a short title and a longer description of the
+	// visualisation, either may be empty
+	Describe() (title, desc string)
+}
+
+// DescribedCanvas is a Canvas that can attach a title and description
+// to a whole drawing
+type DescribedCanvas interface {
+	Canvas
+	// Describe sets the title and description of the next drawing to be
+	// started
+	Describe(title, desc string)
+}
+
+// DarkModer is a visualisation that has colours for viewers who prefer
+// a dark colour scheme
+type DarkModer interface {
+	// DarkColours maps the colours the visualisation draws with to
+	// their dark counterparts, it may be nil
+	DarkColours() ColourMap
+}
+
+// DarkModeCanvas is a Canvas that can recolour a drawing to follow the
+// viewer's colour scheme
+type DarkModeCanvas interface {
+	Canvas
+	// DarkMode sets the colours the next drawing to be started takes
+	// on in a dark colour scheme
+	DarkMode(colours ColourMap)
+}
+
+// DrawTo validates `d` and draws it onto `c` as a whole drawing at its
+// preferred size. nothing is drawn if it is invalid. the drawing is
+// described and given dark colours when both `d` and `c` support it
+func DrawTo(c Canvas, d Drawer) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	if dd, ok := d.(Describer); ok {
+		if dc, ok := c.(DescribedCanvas); ok {
+			dc.Describe(dd.Describe())
+		}
+	}
+	if dm, ok := d.(DarkModer); ok {
+		if dc, ok := c.(DarkModeCanvas); ok {
+			if colours := dm.DarkColours(); len(colours) > 0 {
+				dc.DarkMode(colours)
+			}
+		}
+	}
+	c.Start(d.PreferredSize())
+	d.Draw(c)
+	return c.End()
+}
@@ -0,0 +1,299 @@
+package visualisations
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type square struct {
+	Size float64
+}
+
+func (s square) Validate() error {
+	v := &ValidationError{}
+	v.Positive("Size", s.Size)
+	return v.Err()
+}
+
+func (s square) PreferredSize() (float64, float64) {
+	return s.Size, s.Size
+}
+
+func (s square) Draw(c Canvas) {
+	c.Group("square")
+	c.Rect(0, 0, s.Size, s.Size, Style{Fill: "red"})
+	c.EndGroup()
+	c.Animate("square", "opacity", 0, 0.5, 1.5, 0)
+}
+
+func TestDrawTo(t *testing.T) {
+	builder := &strings.Builder{}
+	if err := DrawTo(NewSVG(builder), square{Size: 10}); err != nil {
+		t.Fatal(err)
+	}
+	got := builder.String()
+	for _, want := range []string{
+		`<svg width="10.00" height="10.00"`,
+		`<g id="square">`,
+		`<rect x="0.00" y="0.00" width="10.00" height="10.00" style="fill:red" />`,
+		`<animate xlink:href="#square" attributeName="opacity" from="0" to="0.5" dur="1.5s" repeatCount="indefinite" />`,
+		`</svg>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %s:\n%s", want, got)
+		}
+	}
+
+	builder.Reset()
+	err := DrawTo(NewSVG(builder), square{})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("got %v, want a validation error", err)
+	}
+	if builder.Len() != 0 {
+		t.Errorf("wrote %q for invalid options", builder.String())
+	}
+}
+
+func TestSVGOptions(t *testing.T) {
+	draw := drawing(func(c Canvas) {
+		c.Path(NewPath().MoveTo(1.234, 5).LineTo(2, 3.456), Style{Stroke: "red"})
+		c.Circle(1.25, 0, 2, Style{Fill: "red"})
+		c.Circle(0, 0, 2, Style{Fill: "red"})
+	})
+	for _, testcase := range []struct {
+		name      string
+		precision *int
+		minify    bool
+		want      string
+	}{
+		{
+			name:      "precision",
+			precision: Int(1),
+			want: `<path d="M1.2,5.0 L2.0,3.5" style="stroke:red" />
+<circle cx="1.2" cy="0.0" r="2.0" style="fill:red" />`,
+		},
+		{
+			name:   "minified",
+			minify: true,
+			want: `<svg width="4" height="4" xmlns="http://www.w3.org/2000/svg">` +
+				`<style>.s0{fill:red}</style><path d="M1.23,5 L2,3.46" style="stroke:red"/>` +
+				`<circle cx="1.25" r="2" class="s0"/><circle r="2" class="s0"/></svg>`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			s := NewSVG(builder)
+			s.Precision = testcase.precision
+			s.Minify = testcase.minify
+			if err := DrawTo(s, draw); err != nil {
+				t.Fatal(err)
+			}
+			if got := builder.String(); !strings.Contains(got, testcase.want) {
+				t.Errorf("output is missing %s:\n%s", testcase.want, got)
+			}
+		})
+	}
+}
+
+// drawing is a Drawer of a fixed size for testing canvas calls
+type drawing func(c Canvas)
+
+func (d drawing) Validate() error {
+	return nil
+}
+
+func (d drawing) PreferredSize() (float64, float64) {
+	return 4, 4
+}
+
+func (d drawing) Draw(c Canvas) {
+	d(c)
+}
+
+func TestSVGResponsive(t *testing.T) {
+	for _, testcase := range []struct {
+		name       string
+		responsive *Responsive
+		want       string
+	}{
+		{
+			name: "fixed",
+			want: `<svg width="4.00" height="4.00"
+     xmlns="http://www.w3.org/2000/svg"`,
+		},
+		{
+			name:       "view box",
+			responsive: &Responsive{},
+			want: `<svg width="4.00" height="4.00"
+     viewBox="0 0 4.00 4.00"
+     xmlns="http://www.w3.org/2000/svg"`,
+		},
+		{
+			name: "full width",
+			responsive: &Responsive{
+				FullWidth:           true,
+				PreserveAspectRatio: "xMinYMin slice",
+			},
+			want: `<svg
+     width="100%"
+     viewBox="0 0 4.00 4.00"
+     preserveAspectRatio="xMinYMin slice"
+     xmlns="http://www.w3.org/2000/svg"
+     xmlns:xlink="http://www.w3.org/1999/xlink">
+<rect`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			s := NewSVG(builder)
+			s.Responsive = testcase.responsive
+			err := DrawTo(s, drawing(func(c Canvas) {
+				c.Rect(0, 0, 4, 4, Style{})
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := builder.String(); !strings.Contains(got, testcase.want) {
+				t.Errorf("output is missing %s:\n%s", testcase.want, got)
+			}
+		})
+	}
+}
+
+func TestSVGClasses(t *testing.T) {
+	draw := drawing(func(c Canvas) {
+		c.Rect(0, 0, 4, 4, Style{Fill: "white", Class: "background"})
+		c.Line(0, 0, 1, 1, Style{Stroke: "red", StrokeWidth: Float(2), Class: "entry"})
+		c.Line(0, 1, 1, 2, Style{Stroke: "blue", StrokeWidth: Float(2), Class: "entry"})
+		c.Line(0, 2, 1, 3, Style{Stroke: "red", StrokeWidth: Float(2), Class: "entry"})
+		c.Circle(1, 1, 1, Style{Fill: "red"})
+	})
+	for _, testcase := range []struct {
+		name   string
+		inline bool
+		want   string
+	}{
+		{
+			name: "stylesheet",
+			want: `<style>
+.background{fill:white}
+.entry{stroke-width:2.0}
+.entry-0{stroke:red}
+.entry-1{stroke:blue}
+</style>
+<rect x="0.00" y="0.00" width="4.00" height="4.00" class="background" />
+<line x1="0.00" y1="0.00" x2="1.00" y2="1.00" class="entry entry-0" />
+<line x1="0.00" y1="1.00" x2="1.00" y2="2.00" class="entry entry-1" />
+<line x1="0.00" y1="2.00" x2="1.00" y2="3.00" class="entry entry-0" />
+<circle cx="1.00" cy="1.00" r="1.00" style="fill:red" />
+</svg>`,
+		},
+		{
+			name:   "inline",
+			inline: true,
+			want: `<rect x="0.00" y="0.00" width="4.00" height="4.00" class="background" style="fill:white" />
+<line x1="0.00" y1="0.00" x2="1.00" y2="1.00" class="entry entry-0" style="stroke:red;stroke-width:2.0" />`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			s := NewSVG(builder)
+			s.Classes = true
+			s.InlineStyles = testcase.inline
+			if err := DrawTo(s, draw); err != nil {
+				t.Fatal(err)
+			}
+			if got := builder.String(); !strings.Contains(got, testcase.want) {
+				t.Errorf("output is missing %s:\n%s", testcase.want, got)
+			}
+		})
+	}
+}
+
+func TestSVGDarkColours(t *testing.T) {
+	draw := drawing(func(c Canvas) {
+		c.Rect(0, 0, 4, 4, Style{Fill: "white", Class: "background"})
+		c.Line(0, 0, 1, 1, Style{Stroke: "#000", StrokeWidth: Float(2), Class: "entry"})
+		c.Line(0, 1, 1, 2, Style{Stroke: "red", StrokeWidth: Float(2), Class: "entry"})
+		c.Circle(1, 1, 1, Style{Fill: "white"})
+	})
+	colours := ColourMap{"white": "#111", "black": "#eee", "red": "#f99", ".entry red": "#c66"}
+	for _, testcase := range []struct {
+		name    string
+		classes bool
+		want    string
+	}{
+		{
+			name: "inline",
+			want: `<style>
+@media (prefers-color-scheme: dark) {
+.background{fill:#111 !important}
+.entry-0{stroke:#eee !important}
+.entry-1{stroke:#c66 !important}
+}
+</style>
+<rect x="0.00" y="0.00" width="4.00" height="4.00" class="background" style="fill:white" />
+<line x1="0.00" y1="0.00" x2="1.00" y2="1.00" class="entry entry-0" style="stroke:#000;stroke-width:2.0" />
+<line x1="0.00" y1="1.00" x2="1.00" y2="2.00" class="entry entry-1" style="stroke:red;stroke-width:2.0" />
+<circle cx="1.00" cy="1.00" r="1.00" style="fill:white" />`,
+		},
+		{
+			name:    "classes",
+			classes: true,
+			want: `<style>
+.background{fill:white}
+.entry{stroke-width:2.0}
+.entry-0{stroke:#000}
+.entry-1{stroke:red}
+@media (prefers-color-scheme: dark) {
+.background{fill:#111}
+.entry-0{stroke:#eee}
+.entry-1{stroke:#c66}
+}
+</style>`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			s := NewSVG(builder)
+			s.Classes = testcase.classes
+			s.DarkColours = colours
+			if err := DrawTo(s, draw); err != nil {
+				t.Fatal(err)
+			}
+			if got := builder.String(); !strings.Contains(got, testcase.want) {
+				t.Errorf("output is missing %s:\n%s", testcase.want, got)
+			}
+		})
+	}
+}
+
+func TestSVGPaint(t *testing.T) {
+	stripes := &Paint{Pattern: &Pattern{Kind: PatternStripes, Colour: "red"}}
+	_, id := stripes.definition()
+	builder := &strings.Builder{}
+	err := DrawTo(NewSVG(builder), drawing(func(c Canvas) {
+		c.Rect(0, 0, 4, 4, Style{Fill: "red", FillPaint: stripes})
+		c.Line(0, 0, 4, 4, Style{Stroke: "red", StrokePaint: stripes, Filter: &Filter{Kind: FilterBlur}})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := builder.String()
+	if n := strings.Count(got, "<pattern"); n != 1 {
+		t.Errorf("paint defined %d times, want once:\n%s", n, got)
+	}
+	for _, want := range []string{
+		`<rect x="0.00" y="0.00" width="4.00" height="4.00" style="fill:url(#` + id + `)" />`,
+		`style="stroke:url(#` + id + `)" filter="url(#vis-`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "<defs>") > strings.Index(got, "<rect") {
+		t.Errorf("paint defined after use:\n%s", got)
+	}
+}
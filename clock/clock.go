@@ -4,46 +4,180 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strconv"
 
-	svg "github.com/ajstarks/svgo/float"
 	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/axis"
+	"github.com/osraige/visualisations/scale"
 )
 
 type ClockOptions struct {
-	canvas             *svg.SVG
-	circumOut          float64
-	circumIn           float64
-	radiOut            float64
-	radiIn             float64
-	Size               float64
-	CenterRadius       float64
-	HandGap            float64
-	Segments           int
-	Colour             string
-	ColourAccent       string
+	canvas       visual.Canvas
+	circumOut    float64
+	circumIn     float64
+	radiOut      float64
+	radiIn       float64
+	Size         float64
+	CenterRadius float64
+	HandGap      float64
+	Segments     int
+	Colour       string
+	// ColourAccent fills the background of the hands. when empty it is
+	// derived by tinting Colour
+	ColourAccent string
+	// HandPaint draws the hands with a gradient or pattern instead of
+	// Colour, on canvases that support it. Colour defaults to the
+	// closest flat colour
+	HandPaint *visual.Paint
+	// HandFilter applies an effect such as a glow to the hands, on
+	// canvases that support it
+	HandFilter         *visual.Filter
 	ColourAverage      string
 	AverageStrokeWidth float64
 	AveragePointRadius float64
 	DataHands          []int
 	DataAverage        []int
-	Debug              bool
-	Animate            bool
+	// Scale maps DataHands and DataAverage onto a proportion of the
+	// length of the hands between 0 and 1. defaults to a linear scale
+	// with a domain of 0 to 100
+	Scale scale.Continuous
+	// ValueAxis draws an axis for Scale along the radius of the clock.
+	// its Scale, Inner and Outer radii default to those of the hands
+	ValueAxis *axis.Radial
+	Debug     bool
+	Animate   bool
+	// ValueFormat writes the values of DataHands in the titles of the
+	// hands and the description, and labels ValueAxis when it has no
+	// Format of its own. defaults to whole numbers
+	ValueFormat func(float64) string
+	// MarkingFont, MarkingFontSize and MarkingColour style the hour
+	// markings, MarkingMutedColour is used for every other marking
+	MarkingFont        string
+	MarkingFontSize    int
+	MarkingColour      string
+	MarkingMutedColour string
+	// Theme provides defaults for any colours, fonts and widths left unset
+	Theme *visual.Theme
+	// DarkTheme recolours what was drawn in Theme's colours when the
+	// viewer prefers a dark colour scheme, on canvases that support it
+	DarkTheme *visual.Theme
+	// Accessibility sets the title and description read out by screen
+	// readers, the description defaults to the busiest and quietest
+	// hours
+	Accessibility visual.Accessibility
+}
+
+func (o *ClockOptions) applyTheme() {
+	if o.Theme != nil {
+		if o.Colour == "" {
+			o.Colour = o.Theme.Primary.String()
+			if o.ColourAccent == "" {
+				o.ColourAccent = o.Theme.Track().String()
+			}
+		}
+		if o.ColourAverage == "" {
+			o.ColourAverage = o.Theme.Accent.String()
+		}
+		if o.AverageStrokeWidth == 0 {
+			o.AverageStrokeWidth = o.Theme.LineWidth
+		}
+		if o.MarkingFont == "" {
+			o.MarkingFont = o.Theme.FontFamily
+		}
+		if o.MarkingFontSize == 0 {
+			o.MarkingFontSize = o.Theme.FontSize
+		}
+		if o.MarkingColour == "" {
+			o.MarkingColour = o.Theme.TextColour.String()
+		}
+		if o.MarkingMutedColour == "" {
+			o.MarkingMutedColour = o.Theme.MutedTextColour.String()
+		}
+	}
+	if o.MarkingFont == "" {
+		o.MarkingFont = "monospace"
+	}
+	if o.MarkingFontSize == 0 {
+		o.MarkingFontSize = 24
+	}
+	if o.MarkingMutedColour == "" {
+		o.MarkingMutedColour = "#eee"
+	}
+}
+
+func (o *ClockOptions) applyDefaults() {
+	if o.Colour == "" {
+		o.Colour = o.HandPaint.Colour()
+	}
+	o.applyTheme()
+	if o.Scale == nil {
+		o.Scale = scale.Linear{Domain: [2]float64{0, 100}, Range: scale.Unit}
+	}
+	if o.ColourAccent == "" {
+		if c, err := visual.ParseColour(o.Colour); err == nil {
+			o.ColourAccent = c.Tint(0.6).String()
+		}
+	}
+}
+
+// Validate reports every option that would stop the clock from being
+// drawn correctly
+func (o ClockOptions) Validate() error {
+	o.applyDefaults()
+	v := &visual.ValidationError{}
+	v.Positive("Size", o.Size)
+	v.NonNegative("CenterRadius", o.CenterRadius)
+	v.Check(o.CenterRadius < o.Size/2, "CenterRadius", o.CenterRadius,
+		"must be less than half of Size")
+	v.NonNegative("HandGap", o.HandGap)
+	v.Positive("Segments", float64(o.Segments))
+	if o.Segments > 0 {
+		// the gap is taken out of the width of each segment at the edge
+		v.Check(o.HandGap < math.Pi*o.Size/float64(o.Segments), "HandGap", o.HandGap,
+			"must be less than the width of a segment")
+	}
+	v.NonNegative("AverageStrokeWidth", o.AverageStrokeWidth)
+	v.NonNegative("AveragePointRadius", o.AveragePointRadius)
+	v.NonNegative("MarkingFontSize", float64(o.MarkingFontSize))
+	checkData := func(field string, data []int) {
+		for i, d := range data {
+			t := o.Scale.Map(float64(d))
+			v.Check(!math.IsNaN(t) && !math.IsInf(t, 0),
+				fmt.Sprintf("%s[%d]", field, i), d, "can not be mapped by Scale")
+		}
+	}
+	checkData("DataHands", o.DataHands)
+	checkData("DataAverage", o.DataAverage)
+	if o.HandPaint != nil {
+		v.Nest("HandPaint", o.HandPaint.Validate())
+	}
+	if o.HandFilter != nil {
+		v.Nest("HandFilter", o.HandFilter.Validate())
+	}
+	if o.ValueAxis != nil {
+		v.Nest("ValueAxis", o.ValueAxis.Validate())
+	}
+	return v.Err()
 }
 
 func (o ClockOptions) drawHands(group string) {
 	handTop := ((o.circumOut / float64(o.Segments)) - o.HandGap) / 2.0
 	handBottom := ((o.circumIn / float64(o.Segments)) - o.HandGap) / 2.0
-	o.canvas.Gid(group)
-	defer o.canvas.Gend()
+	o.canvas.Group(group)
+	defer o.canvas.EndGroup()
 	o.iterDataOnSeg(o.DataHands, func(height int, a float64) {
-		widthSc := visual.ScaleRange(float64(height), 0, 100, handBottom, handTop)
-		heightSc := visual.ScaleRange(float64(height), 0, 100, o.radiIn, o.radiOut)
+		t := o.Scale.Map(float64(height))
+		widthSc := visual.ScaleRange(t, 0, 1, handBottom, handTop)
+		heightSc := visual.ScaleRange(t, 0, 1, o.radiIn, o.radiOut)
 		o.canvas.TranslateRotate(o.radiOut, o.radiOut, float64(a-180))
+		if !o.Accessibility.Disabled {
+			o.canvas.Title(timeOfDay(a) + ": " + o.formatValue(height))
+		}
 		// draw the background of the hand
 		o.canvas.Polyline(
 			[]float64{-handBottom, handBottom, handTop, -handTop},
 			[]float64{o.radiIn, o.radiIn, o.radiOut, o.radiOut},
-			visual.ParseFill(o.ColourAccent),
+			visual.Style{Fill: o.ColourAccent, Class: "clock-hand-background"},
 		)
 		// draw the hand itself. height depends on the current
 		// data point. the width of the side of the trapezoid
@@ -51,50 +185,60 @@ func (o ClockOptions) drawHands(group string) {
 		o.canvas.Polyline(
 			[]float64{-handBottom, handBottom, widthSc, -widthSc},
 			[]float64{o.radiIn, o.radiIn, heightSc, heightSc},
-			visual.ParseFill(o.Colour),
+			visual.Style{
+				Fill:      o.Colour,
+				FillPaint: o.HandPaint,
+				Filter:    o.HandFilter,
+				Class:     "clock-hand",
+			},
 		)
-		o.canvas.Gend()
+		o.canvas.EndGroup()
 	})
 }
 
 func (o ClockOptions) drawHourMarkings(group string) {
-	textStyle := []string{
-		visual.ParseFontFamily("monospace"),
-		visual.ParseFontSize(24),
-		visual.ParseTextAnchor("middle"),
-		visual.ParseDominantBaseline("central"),
-	}
-	o.canvas.Gid(group)
-	defer o.canvas.Gend()
+	textStyle := visual.Style{
+		Fill:             o.MarkingColour,
+		FontFamily:       o.MarkingFont,
+		FontSize:         visual.Int(o.MarkingFontSize),
+		TextAnchor:       "middle",
+		DominantBaseline: "central",
+		Class:            "clock-marking",
+	}
+	o.canvas.Group(group)
+	defer o.canvas.EndGroup()
 	// increment on every 45 degress for positon of marking, and every
 	// 3 hours for the text itself
 	for x, t := -90, 0; x < 270; x, t = x+45, t+3 {
 		px, py := visual.PointOnCircum(o.radiOut, o.radiOut, o.radiIn-20, float64(x))
-		opts := append([]string{}, textStyle...)
+		style := textStyle
 		// grey out every other marking
 		if t%6 != 0 {
-			opts = append(opts, visual.ParseFill("#eee"))
+			style = style.Override(visual.Style{Fill: o.MarkingMutedColour})
 		}
-		o.canvas.Text(px, py, fmt.Sprintf("%02d", t), visual.ParseStyles(opts...))
+		o.canvas.Text(px, py, fmt.Sprintf("%02d", t), style)
 	}
 }
 
 func (o ClockOptions) drawAverage(group string) {
-	strokeStyle := visual.ParseStyles(
-		visual.ParseFill("none"),
-		visual.ParseStroke(o.ColourAverage),
-		visual.ParseStrokeWidth(o.AverageStrokeWidth),
-	)
+	strokeStyle := visual.Style{
+		Fill:        "none",
+		Stroke:      o.ColourAverage,
+		StrokeWidth: visual.Float(o.AverageStrokeWidth),
+		Class:       "clock-average",
+	}
 	xs := []float64{}
 	ys := []float64{}
-	o.canvas.Gid(group)
-	defer o.canvas.Gend()
+	o.canvas.Group(group)
+	defer o.canvas.EndGroup()
 	o.iterDataOnSeg(o.DataAverage, func(height int, a float64) {
-		heightSc := visual.ScaleRange(float64(height), 0, 100, o.radiIn, o.radiOut)
+		t := o.Scale.Map(float64(height))
+		heightSc := visual.ScaleRange(t, 0, 1, o.radiIn, o.radiOut)
 		px, py := visual.PointOnCircum(o.radiOut, o.radiOut, heightSc, float64(a-90))
 		xs = append(xs, px)
 		ys = append(ys, py)
-		o.canvas.Circle(px, py, o.AveragePointRadius, visual.ParseFill(o.ColourAverage))
+		o.canvas.Circle(px, py, o.AveragePointRadius,
+			visual.Style{Fill: o.ColourAverage, Class: "clock-average-point"})
 	})
 	// wrap the last trend data segment to the first to connect the dots
 	xs = append(xs, xs[0])
@@ -103,16 +247,32 @@ func (o ClockOptions) drawAverage(group string) {
 }
 
 func (o ClockOptions) drawDebug(group string) {
-	strokeStyle := visual.ParseStyles(
-		visual.ParseFill("none"),
-		visual.ParseStroke("black"),
-	)
-	o.canvas.Gid(group)
-	defer o.canvas.Gend()
+	strokeStyle := visual.Style{
+		Fill:   "none",
+		Stroke: "black",
+	}
+	o.canvas.Group(group)
+	defer o.canvas.EndGroup()
 	o.canvas.Line(o.radiOut, 0, o.radiOut, o.Size, strokeStyle)
 	o.canvas.Line(0, o.radiOut, o.Size, o.radiOut, strokeStyle)
 }
 
+func (o ClockOptions) drawValueAxis(group string) {
+	a := *o.ValueAxis
+	if a.Scale == nil {
+		a.Scale = o.Scale
+	}
+	if a.Format == nil {
+		a.Format = o.ValueFormat
+	}
+	if a.Inner == 0 && a.Outer == 0 {
+		a.Inner, a.Outer = o.radiIn, o.radiOut
+	}
+	o.canvas.Group(group)
+	defer o.canvas.EndGroup()
+	a.Draw(o.canvas, o.radiOut, o.radiOut)
+}
+
 func (o ClockOptions) iterDataOnSeg(data []int, cb func(int, float64)) {
 	angleInc := 360.0 / float64(o.Segments)
 	for x := 0.0; x < 360.0; x += angleInc {
@@ -124,24 +284,95 @@ func (o ClockOptions) iterDataOnSeg(data []int, cb func(int, float64)) {
 	}
 }
 
-func Clock(out io.Writer, opts ClockOptions) {
-	canvas := svg.New(out)
-	canvas.Start(opts.Size, opts.Size)
-	defer canvas.End()
-	opts.canvas = canvas
-	canvas.Gid("root")
-	defer canvas.Gend()
-	opts.radiOut = opts.Size / 2.0
-	opts.radiIn = opts.CenterRadius
-	opts.circumOut = 2.0 * math.Pi * opts.radiOut
-	opts.circumIn = 2.0 * math.Pi * opts.radiIn
-	opts.drawHands("hands")
-	opts.drawHourMarkings("hour-markings")
-	if opts.Debug {
-		opts.drawDebug("debug")
+// formatValue writes a value of the data with ValueFormat
+func (o ClockOptions) formatValue(v int) string {
+	if o.ValueFormat == nil {
+		return strconv.Itoa(v)
 	}
-	opts.drawAverage("average")
-	if opts.Animate {
-		canvas.Animate("#average", "opacity", 0, 1, 0.75, 1)
+	return o.ValueFormat(float64(v))
+}
+
+// timeOfDay formats the angle `a` in degrees around the clock as a
+// 24 hour time
+func timeOfDay(a float64) string {
+	minutes := int(math.Round(a/360*24*60)) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// Describe summarises the clock for screen readers, naming the busiest
+// and quietest hands
+func (o ClockOptions) Describe() (title, desc string) {
+	if len(o.DataHands) > 0 && o.Segments > 0 {
+		busiest, quietest := 0.0, 0.0
+		most, least := o.DataHands[0], o.DataHands[0]
+		o.iterDataOnSeg(o.DataHands, func(height int, a float64) {
+			if height > most {
+				busiest, most = a, height
+			}
+			if height < least {
+				quietest, least = a, height
+			}
+		})
+		desc = fmt.Sprintf("Busiest hour %s with %s, quietest %s with %s",
+			timeOfDay(busiest), o.formatValue(most), timeOfDay(quietest), o.formatValue(least))
 	}
+	return o.Accessibility.Describe("Clock", desc)
+}
+
+// DarkColours maps the colours of Theme to those of DarkTheme
+func (o ClockOptions) DarkColours() visual.ColourMap {
+	if o.Theme == nil || o.DarkTheme == nil {
+		return nil
+	}
+	return o.Theme.DarkColours(*o.DarkTheme)
+}
+
+func init() {
+	visual.Register("clock", func(decode visual.Decoder) (visual.Renderer, error) {
+		opts := ClockOptions{}
+		err := decode(&opts)
+		return opts, err
+	})
+}
+
+// Render draws the clock as an svg
+func (o ClockOptions) Render(out io.Writer) error {
+	return Clock(out, o)
+}
+
+func (o ClockOptions) PreferredSize() (float64, float64) {
+	return o.Size, o.Size
+}
+
+// Draw draws the clock onto `c`
+func (o ClockOptions) Draw(c visual.Canvas) {
+	o.canvas = c
+	c.Group("root")
+	defer c.EndGroup()
+	if o.Theme != nil && o.Theme.HasBackground() {
+		c.Rect(0, 0, o.Size, o.Size, o.Theme.BackgroundStyle())
+	}
+	o.radiOut = o.Size / 2.0
+	o.radiIn = o.CenterRadius
+	o.circumOut = 2.0 * math.Pi * o.radiOut
+	o.circumIn = 2.0 * math.Pi * o.radiIn
+	o.applyDefaults()
+	o.drawHands("hands")
+	o.drawHourMarkings("hour-markings")
+	if o.Debug {
+		o.drawDebug("debug")
+	}
+	o.drawAverage("average")
+	if o.ValueAxis != nil {
+		o.drawValueAxis("value-axis")
+	}
+	if o.Animate {
+		c.Animate("average", "opacity", 0, 1, 0.75, 1)
+	}
+}
+
+// Clock generates a clock from the given options. nothing is written if
+// the options are invalid, otherwise any error from `out` is returned
+func Clock(out io.Writer, opts ClockOptions) error {
+	return visual.DrawTo(visual.NewSVG(out), opts)
 }
@@ -1,12 +1,17 @@
 package clock
 
 import (
+	"errors"
 	"flag"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/kylelemons/godebug/diff"
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/axis"
+	"github.com/osraige/visualisations/format"
+	"github.com/osraige/visualisations/scale"
 	"github.com/osraige/visualisations/visualtest"
 )
 
@@ -64,12 +69,76 @@ func TestGenerate(t *testing.T) {
 					6, 5, 4, 3, 2, 1,
 				},
 			},
+		}, {
+			golden: "derived-accent",
+			clockOptions: ClockOptions{
+				Size:               500,
+				CenterRadius:       100,
+				HandGap:            3,
+				Segments:           24,
+				Colour:             "#33065d",
+				ColourAverage:      "orange",
+				AverageStrokeWidth: 3,
+				AveragePointRadius: 5.5,
+				DataHands:          []int{},
+				DataAverage:        []int{},
+			},
+		}, {
+			golden: "light-theme",
+			clockOptions: ClockOptions{
+				Size:               500,
+				CenterRadius:       100,
+				HandGap:            3,
+				Segments:           24,
+				AveragePointRadius: 5.5,
+				DataHands:          []int{20, 40, 60, 80},
+				DataAverage:        []int{50, 30},
+				Theme:              &visual.LightTheme,
+			},
+		}, {
+			golden: "log-scale",
+			clockOptions: ClockOptions{
+				Size:               500,
+				CenterRadius:       100,
+				HandGap:            3,
+				Segments:           12,
+				Colour:             "#33065d",
+				ColourAverage:      "orange",
+				AverageStrokeWidth: 3,
+				AveragePointRadius: 5.5,
+				DataHands:          []int{1, 10, 100, 1000, 10000},
+				DataAverage:        []int{10, 100},
+				Scale: scale.Log{
+					Domain: [2]float64{1, 10000},
+					Range:  scale.Unit,
+				},
+			},
+		}, {
+			golden: "value-axis",
+			clockOptions: ClockOptions{
+				Size:               500,
+				CenterRadius:       100,
+				HandGap:            3,
+				Segments:           24,
+				Colour:             "#33065d",
+				ColourAverage:      "orange",
+				AverageStrokeWidth: 3,
+				AveragePointRadius: 5.5,
+				DataHands:          []int{30, 60, 90, 45},
+				DataAverage:        []int{50},
+				ValueAxis: &axis.Radial{
+					Angle: -90,
+					Rings: true,
+				},
+			},
 		},
 	} {
 		t.Run(testcase.golden, func(t *testing.T) {
 			// t.Parallel()
 			builder := &strings.Builder{}
-			Clock(builder, testcase.clockOptions)
+			if err := Clock(builder, testcase.clockOptions); err != nil {
+				t.Fatal(err)
+			}
 			got := builder.String()
 			want := visualtest.GoldenValue(t, testcase.golden, got, *update)
 			if got != want {
@@ -78,3 +147,133 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribe(t *testing.T) {
+	for _, testcase := range []struct {
+		name        string
+		options     ClockOptions
+		title, desc string
+	}{
+		{
+			name:    "empty",
+			options: ClockOptions{Segments: 24},
+			title:   "Clock",
+		},
+		{
+			name: "hourly",
+			options: ClockOptions{
+				Segments:  24,
+				DataHands: []int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3, 2, 3, 8, 4, 6, 2, 6, 4},
+			},
+			title: "Clock",
+			desc:  "Busiest hour 05:00 with 9, quietest 01:00 with 1",
+		},
+		{
+			name: "half hourly",
+			options: ClockOptions{
+				Segments:  48,
+				DataHands: []int{1, 2, 3, 0},
+			},
+			title: "Clock",
+			desc:  "Busiest hour 01:00 with 3, quietest 01:30 with 0",
+		},
+		{
+			name: "formatted",
+			options: ClockOptions{
+				Segments:    24,
+				DataHands:   []int{1500, 250},
+				ValueFormat: format.SI(2).Suffix(" req"),
+			},
+			title: "Clock",
+			desc:  "Busiest hour 00:00 with 1.5k req, quietest 01:00 with 250 req",
+		},
+		{
+			name: "disabled",
+			options: ClockOptions{
+				Segments:      24,
+				DataHands:     []int{1},
+				Accessibility: visual.Accessibility{Disabled: true},
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			title, desc := testcase.options.Describe()
+			if title != testcase.title || desc != testcase.desc {
+				t.Errorf("got %q, %q, want %q, %q", title, desc, testcase.title, testcase.desc)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		opts   ClockOptions
+		fields []string
+	}{
+		{
+			name:   "no segments",
+			opts:   ClockOptions{Size: 500, CenterRadius: 100},
+			fields: []string{"Segments"},
+		}, {
+			name:   "centre too large",
+			opts:   ClockOptions{Size: 100, CenterRadius: 60, Segments: 24, HandGap: -1},
+			fields: []string{"CenterRadius", "HandGap"},
+		}, {
+			name: "unmappable data",
+			opts: ClockOptions{
+				Size:         500,
+				CenterRadius: 100,
+				Segments:     24,
+				DataHands:    []int{1, 0},
+				DataAverage:  []int{10},
+				Scale: scale.Log{
+					Domain: [2]float64{1, 10000},
+					Range:  scale.Unit,
+				},
+			},
+			fields: []string{"DataHands[1]"},
+		}, {
+			name:   "gap wider than a segment",
+			opts:   ClockOptions{Size: 100, CenterRadius: 20, Segments: 24, HandGap: 14},
+			fields: []string{"HandGap"},
+		}, {
+			name: "invalid value axis",
+			opts: ClockOptions{
+				Size:         100,
+				CenterRadius: 20,
+				Segments:     24,
+				ValueAxis:    &axis.Radial{Inner: 40, Outer: 30},
+			},
+			fields: []string{"ValueAxis.Outer"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			err := Clock(builder, testcase.opts)
+			verr, ok := err.(*visual.ValidationError)
+			if !ok {
+				t.Fatalf("got %v, want a *visual.ValidationError", err)
+			}
+			var fields []string
+			for _, fe := range verr.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if strings.Join(fields, " ") != strings.Join(testcase.fields, " ") {
+				t.Errorf("got invalid fields %v, want %v", fields, testcase.fields)
+			}
+			if builder.Len() != 0 {
+				t.Errorf("wrote %d bytes for invalid options", builder.Len())
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	dropped := errors.New("connection dropped")
+	w := &visualtest.FailingWriter{After: 100, Err: dropped}
+	err := Clock(w, ClockOptions{Size: 100, CenterRadius: 20, Segments: 24})
+	if err != dropped {
+		t.Errorf("got %v, want %v", err, dropped)
+	}
+}
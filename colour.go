@@ -0,0 +1,494 @@
+package visualisations
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Colour is an sRGB colour with an alpha channel. every channel is in
+// the range 0 to 1
+type Colour struct {
+	R, G, B, A float64
+}
+
+var (
+	White = Colour{1, 1, 1, 1}
+	Black = Colour{0, 0, 0, 1}
+)
+
+// RGB creates an opaque colour from 8 bit channels
+func RGB(r, g, b uint8) Colour {
+	return Colour{float64(r) / 255, float64(g) / 255, float64(b) / 255, 1}
+}
+
+// RGBA creates a colour from 8 bit channels and an alpha between 0 and 1
+func RGBA(r, g, b uint8, a float64) Colour {
+	return RGB(r, g, b).WithAlpha(a)
+}
+
+// HSL creates an opaque colour from a hue in degrees and a saturation
+// and lightness between 0 and 1
+func HSL(h, s, l float64) Colour {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s, l = clamp01(s), clamp01(l)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - chroma/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return Colour{r + m, g + m, b + m, 1}
+}
+
+// MustParseColour is like ParseColour but panics if `s` is not a
+// valid colour. it is intended for package level variables
+func MustParseColour(s string) Colour {
+	c, err := ParseColour(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ParseColour parses a css colour. named colours, #rgb, #rgba,
+// #rrggbb, #rrggbbaa, rgb(), rgba(), hsl() and hsla() are supported
+func ParseColour(s string) (Colour, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColours[str]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(str, "#") {
+		c, ok := parseHex(str[1:])
+		if !ok {
+			return Colour{}, fmt.Errorf("visualisations: invalid hex colour %q", s)
+		}
+		return c, nil
+	}
+	open := strings.IndexByte(str, '(')
+	if open < 0 || !strings.HasSuffix(str, ")") {
+		return Colour{}, fmt.Errorf("visualisations: unknown colour %q", s)
+	}
+	fn := str[:open]
+	args := strings.FieldsFunc(str[open+1:len(str)-1], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/'
+	})
+	var c Colour
+	var ok bool
+	switch fn {
+	case "rgb", "rgba":
+		c, ok = parseRGBArgs(args)
+	case "hsl", "hsla":
+		c, ok = parseHSLArgs(args)
+	}
+	if !ok {
+		return Colour{}, fmt.Errorf("visualisations: invalid colour function %q", s)
+	}
+	return c, nil
+}
+
+func parseHex(hex string) (Colour, bool) {
+	switch len(hex) {
+	case 3, 4:
+		expanded := make([]byte, 0, len(hex)*2)
+		for i := 0; i < len(hex); i++ {
+			expanded = append(expanded, hex[i], hex[i])
+		}
+		hex = string(expanded)
+	case 6, 8:
+	default:
+		return Colour{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Colour{}, false
+	}
+	if len(hex) == 6 {
+		v = v<<8 | 0xff
+	}
+	return RGBA(uint8(v>>24), uint8(v>>16), uint8(v>>8), float64(v&0xff)/255), true
+}
+
+func parseRGBArgs(args []string) (Colour, bool) {
+	if len(args) != 3 && len(args) != 4 {
+		return Colour{}, false
+	}
+	channels := [3]float64{}
+	for i := range channels {
+		v, ok := parseChannel(args[i], 255)
+		if !ok {
+			return Colour{}, false
+		}
+		channels[i] = v
+	}
+	alpha := 1.0
+	if len(args) == 4 {
+		a, ok := parseChannel(args[3], 1)
+		if !ok {
+			return Colour{}, false
+		}
+		alpha = a
+	}
+	return Colour{channels[0], channels[1], channels[2], alpha}, true
+}
+
+func parseHSLArgs(args []string) (Colour, bool) {
+	if len(args) != 3 && len(args) != 4 {
+		return Colour{}, false
+	}
+	h, ok := parseFinite(strings.TrimSuffix(args[0], "deg"))
+	if !ok {
+		return Colour{}, false
+	}
+	s, ok := parseChannel(args[1], 1)
+	if !ok {
+		return Colour{}, false
+	}
+	l, ok := parseChannel(args[2], 1)
+	if !ok {
+		return Colour{}, false
+	}
+	alpha := 1.0
+	if len(args) == 4 {
+		if alpha, ok = parseChannel(args[3], 1); !ok {
+			return Colour{}, false
+		}
+	}
+	return HSL(h, s, l).WithAlpha(alpha), true
+}
+
+// parseChannel parses either a percentage or a plain number out of
+// `max`, returning a value between 0 and 1
+func parseChannel(arg string, max float64) (float64, bool) {
+	if strings.HasSuffix(arg, "%") {
+		arg, max = arg[:len(arg)-1], 100
+	}
+	v, ok := parseFinite(arg)
+	return clamp01(v / max), ok
+}
+
+// parseFinite parses a number, rejecting the NaN and infinities that
+// strconv accepts
+func parseFinite(arg string) (float64, bool) {
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func channel8(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+// RGBA8 returns the channels of `c` as 8 bit values
+func (c Colour) RGBA8() (r, g, b, a uint8) {
+	return channel8(c.R), channel8(c.G), channel8(c.B), channel8(c.A)
+}
+
+// Hex formats `c` as #rrggbb, or #rrggbbaa if it is not opaque
+func (c Colour) Hex() string {
+	r, g, b, a := c.RGBA8()
+	if a == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", r, g, b, a)
+}
+
+// String formats `c` as #rrggbb, or as rgba() if it is not opaque
+func (c Colour) String() string {
+	r, g, b, a := c.RGBA8()
+	if a == 0xff {
+		return c.Hex()
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", r, g, b,
+		strconv.FormatFloat(math.Round(clamp01(c.A)*1000)/1000, 'f', -1, 64))
+}
+
+// MarshalText encodes `c` as a css colour string
+func (c Colour) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes any css colour accepted by ParseColour
+func (c *Colour) UnmarshalText(text []byte) error {
+	parsed, err := ParseColour(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// HSL returns the hue in degrees, and the saturation and lightness
+// between 0 and 1 of `c`
+func (c Colour) HSL() (h, s, l float64) {
+	max := math.Max(c.R, math.Max(c.G, c.B))
+	min := math.Min(c.R, math.Min(c.G, c.B))
+	l = (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+	d := max - min
+	s = d / (1 - math.Abs(2*l-1))
+	switch max {
+	case c.R:
+		h = math.Mod((c.G-c.B)/d, 6)
+	case c.G:
+		h = (c.B-c.R)/d + 2
+	default:
+		h = (c.R-c.G)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// WithAlpha returns `c` with its alpha channel set to `a`
+func (c Colour) WithAlpha(a float64) Colour {
+	c.A = clamp01(a)
+	return c
+}
+
+// Lighten increases the hsl lightness of `c` by `amount` (0 to 1)
+func (c Colour) Lighten(amount float64) Colour {
+	h, s, l := c.HSL()
+	return HSL(h, s, l+amount).WithAlpha(c.A)
+}
+
+// Darken decreases the hsl lightness of `c` by `amount` (0 to 1)
+func (c Colour) Darken(amount float64) Colour {
+	return c.Lighten(-amount)
+}
+
+// Mix linearly interpolates between `c` and `other`. a `t` of 0
+// returns `c` and a `t` of 1 returns `other`
+func (c Colour) Mix(other Colour, t float64) Colour {
+	t = clamp01(t)
+	lerp := func(a, b float64) float64 { return a + (b-a)*t }
+	return Colour{
+		lerp(c.R, other.R),
+		lerp(c.G, other.G),
+		lerp(c.B, other.B),
+		lerp(c.A, other.A),
+	}
+}
+
+// Tint mixes `c` with white by `amount` (0 to 1)
+func (c Colour) Tint(amount float64) Colour {
+	return c.Mix(White.WithAlpha(c.A), amount)
+}
+
+// Shade mixes `c` with black by `amount` (0 to 1)
+func (c Colour) Shade(amount float64) Colour {
+	return c.Mix(Black.WithAlpha(c.A), amount)
+}
+
+// Luminance is the WCAG relative luminance of `c`, ignoring alpha
+func (c Colour) Luminance() float64 {
+	linear := func(v float64) float64 {
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// ContrastRatio is the WCAG contrast ratio between `a` and `b`,
+// ranging from 1 to 21
+func ContrastRatio(a, b Colour) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// ReadableOn returns whichever of black or white has the higher
+// contrast against `background`
+func ReadableOn(background Colour) Colour {
+	if ContrastRatio(background, Black) >= ContrastRatio(background, White) {
+		return Black
+	}
+	return White
+}
+
+func hexColour(v uint32) Colour {
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v))
+}
+
+// namedColours are the css level 4 named colours
+var namedColours = map[string]Colour{
+	"transparent":          {0, 0, 0, 0},
+	"aliceblue":            hexColour(0xf0f8ff),
+	"antiquewhite":         hexColour(0xfaebd7),
+	"aqua":                 hexColour(0x00ffff),
+	"aquamarine":           hexColour(0x7fffd4),
+	"azure":                hexColour(0xf0ffff),
+	"beige":                hexColour(0xf5f5dc),
+	"bisque":               hexColour(0xffe4c4),
+	"black":                hexColour(0x000000),
+	"blanchedalmond":       hexColour(0xffebcd),
+	"blue":                 hexColour(0x0000ff),
+	"blueviolet":           hexColour(0x8a2be2),
+	"brown":                hexColour(0xa52a2a),
+	"burlywood":            hexColour(0xdeb887),
+	"cadetblue":            hexColour(0x5f9ea0),
+	"chartreuse":           hexColour(0x7fff00),
+	"chocolate":            hexColour(0xd2691e),
+	"coral":                hexColour(0xff7f50),
+	"cornflowerblue":       hexColour(0x6495ed),
+	"cornsilk":             hexColour(0xfff8dc),
+	"crimson":              hexColour(0xdc143c),
+	"cyan":                 hexColour(0x00ffff),
+	"darkblue":             hexColour(0x00008b),
+	"darkcyan":             hexColour(0x008b8b),
+	"darkgoldenrod":        hexColour(0xb8860b),
+	"darkgray":             hexColour(0xa9a9a9),
+	"darkgreen":            hexColour(0x006400),
+	"darkgrey":             hexColour(0xa9a9a9),
+	"darkkhaki":            hexColour(0xbdb76b),
+	"darkmagenta":          hexColour(0x8b008b),
+	"darkolivegreen":       hexColour(0x556b2f),
+	"darkorange":           hexColour(0xff8c00),
+	"darkorchid":           hexColour(0x9932cc),
+	"darkred":              hexColour(0x8b0000),
+	"darksalmon":           hexColour(0xe9967a),
+	"darkseagreen":         hexColour(0x8fbc8f),
+	"darkslateblue":        hexColour(0x483d8b),
+	"darkslategray":        hexColour(0x2f4f4f),
+	"darkslategrey":        hexColour(0x2f4f4f),
+	"darkturquoise":        hexColour(0x00ced1),
+	"darkviolet":           hexColour(0x9400d3),
+	"deeppink":             hexColour(0xff1493),
+	"deepskyblue":          hexColour(0x00bfff),
+	"dimgray":              hexColour(0x696969),
+	"dimgrey":              hexColour(0x696969),
+	"dodgerblue":           hexColour(0x1e90ff),
+	"firebrick":            hexColour(0xb22222),
+	"floralwhite":          hexColour(0xfffaf0),
+	"forestgreen":          hexColour(0x228b22),
+	"fuchsia":              hexColour(0xff00ff),
+	"gainsboro":            hexColour(0xdcdcdc),
+	"ghostwhite":           hexColour(0xf8f8ff),
+	"gold":                 hexColour(0xffd700),
+	"goldenrod":            hexColour(0xdaa520),
+	"gray":                 hexColour(0x808080),
+	"green":                hexColour(0x008000),
+	"greenyellow":          hexColour(0xadff2f),
+	"grey":                 hexColour(0x808080),
+	"honeydew":             hexColour(0xf0fff0),
+	"hotpink":              hexColour(0xff69b4),
+	"indianred":            hexColour(0xcd5c5c),
+	"indigo":               hexColour(0x4b0082),
+	"ivory":                hexColour(0xfffff0),
+	"khaki":                hexColour(0xf0e68c),
+	"lavender":             hexColour(0xe6e6fa),
+	"lavenderblush":        hexColour(0xfff0f5),
+	"lawngreen":            hexColour(0x7cfc00),
+	"lemonchiffon":         hexColour(0xfffacd),
+	"lightblue":            hexColour(0xadd8e6),
+	"lightcoral":           hexColour(0xf08080),
+	"lightcyan":            hexColour(0xe0ffff),
+	"lightgoldenrodyellow": hexColour(0xfafad2),
+	"lightgray":            hexColour(0xd3d3d3),
+	"lightgreen":           hexColour(0x90ee90),
+	"lightgrey":            hexColour(0xd3d3d3),
+	"lightpink":            hexColour(0xffb6c1),
+	"lightsalmon":          hexColour(0xffa07a),
+	"lightseagreen":        hexColour(0x20b2aa),
+	"lightskyblue":         hexColour(0x87cefa),
+	"lightslategray":       hexColour(0x778899),
+	"lightslategrey":       hexColour(0x778899),
+	"lightsteelblue":       hexColour(0xb0c4de),
+	"lightyellow":          hexColour(0xffffe0),
+	"lime":                 hexColour(0x00ff00),
+	"limegreen":            hexColour(0x32cd32),
+	"linen":                hexColour(0xfaf0e6),
+	"magenta":              hexColour(0xff00ff),
+	"maroon":               hexColour(0x800000),
+	"mediumaquamarine":     hexColour(0x66cdaa),
+	"mediumblue":           hexColour(0x0000cd),
+	"mediumorchid":         hexColour(0xba55d3),
+	"mediumpurple":         hexColour(0x9370db),
+	"mediumseagreen":       hexColour(0x3cb371),
+	"mediumslateblue":      hexColour(0x7b68ee),
+	"mediumspringgreen":    hexColour(0x00fa9a),
+	"mediumturquoise":      hexColour(0x48d1cc),
+	"mediumvioletred":      hexColour(0xc71585),
+	"midnightblue":         hexColour(0x191970),
+	"mintcream":            hexColour(0xf5fffa),
+	"mistyrose":            hexColour(0xffe4e1),
+	"moccasin":             hexColour(0xffe4b5),
+	"navajowhite":          hexColour(0xffdead),
+	"navy":                 hexColour(0x000080),
+	"oldlace":              hexColour(0xfdf5e6),
+	"olive":                hexColour(0x808000),
+	"olivedrab":            hexColour(0x6b8e23),
+	"orange":               hexColour(0xffa500),
+	"orangered":            hexColour(0xff4500),
+	"orchid":               hexColour(0xda70d6),
+	"palegoldenrod":        hexColour(0xeee8aa),
+	"palegreen":            hexColour(0x98fb98),
+	"paleturquoise":        hexColour(0xafeeee),
+	"palevioletred":        hexColour(0xdb7093),
+	"papayawhip":           hexColour(0xffefd5),
+	"peachpuff":            hexColour(0xffdab9),
+	"peru":                 hexColour(0xcd853f),
+	"pink":                 hexColour(0xffc0cb),
+	"plum":                 hexColour(0xdda0dd),
+	"powderblue":           hexColour(0xb0e0e6),
+	"purple":               hexColour(0x800080),
+	"rebeccapurple":        hexColour(0x663399),
+	"red":                  hexColour(0xff0000),
+	"rosybrown":            hexColour(0xbc8f8f),
+	"royalblue":            hexColour(0x4169e1),
+	"saddlebrown":          hexColour(0x8b4513),
+	"salmon":               hexColour(0xfa8072),
+	"sandybrown":           hexColour(0xf4a460),
+	"seagreen":             hexColour(0x2e8b57),
+	"seashell":             hexColour(0xfff5ee),
+	"sienna":               hexColour(0xa0522d),
+	"silver":               hexColour(0xc0c0c0),
+	"skyblue":              hexColour(0x87ceeb),
+	"slateblue":            hexColour(0x6a5acd),
+	"slategray":            hexColour(0x708090),
+	"slategrey":            hexColour(0x708090),
+	"snow":                 hexColour(0xfffafa),
+	"springgreen":          hexColour(0x00ff7f),
+	"steelblue":            hexColour(0x4682b4),
+	"tan":                  hexColour(0xd2b48c),
+	"teal":                 hexColour(0x008080),
+	"thistle":              hexColour(0xd8bfd8),
+	"tomato":               hexColour(0xff6347),
+	"turquoise":            hexColour(0x40e0d0),
+	"violet":               hexColour(0xee82ee),
+	"wheat":                hexColour(0xf5deb3),
+	"white":                hexColour(0xffffff),
+	"whitesmoke":           hexColour(0xf5f5f5),
+	"yellow":               hexColour(0xffff00),
+	"yellowgreen":          hexColour(0x9acd32),
+}
@@ -0,0 +1,70 @@
+package visualisations
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseColour(t *testing.T) {
+	for _, testcase := range []struct {
+		in   string
+		want string
+	}{
+		{in: "white", want: "#ffffff"},
+		{in: "RebeccaPurple", want: "#663399"},
+		{in: "transparent", want: "rgba(0,0,0,0)"},
+		{in: "#abc", want: "#aabbcc"},
+		{in: "#abcd", want: "rgba(170,187,204,0.867)"},
+		{in: "#33065d", want: "#33065d"},
+		{in: "#33065d80", want: "rgba(51,6,93,0.502)"},
+		{in: "rgb(255, 128, 0)", want: "#ff8000"},
+		{in: "rgb(100% 50% 0%)", want: "#ff8000"},
+		{in: "rgba(255,0,0,0.5)", want: "rgba(255,0,0,0.5)"},
+		{in: "rgb(0 0 255 / 25%)", want: "rgba(0,0,255,0.25)"},
+		{in: "hsl(120, 100%, 25%)", want: "#008000"},
+		{in: "hsla(0deg 100% 50% / 0.5)", want: "rgba(255,0,0,0.5)"},
+	} {
+		t.Run(testcase.in, func(t *testing.T) {
+			c, err := ParseColour(testcase.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := c.String(); got != testcase.want {
+				t.Errorf("got %s, want %s", got, testcase.want)
+			}
+		})
+	}
+	for _, invalid := range []string{
+		"", "notacolour", "#12", "#ggg", "rgb(1,2)", "cmyk(0,0,0,0)",
+		"rgb(NaN,0,0)", "rgb(0,Inf,0)", "rgb(0 0 0 / NaN%)", "hsl(Inf,50%,50%)",
+	} {
+		if _, err := ParseColour(invalid); err == nil {
+			t.Errorf("expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestColourManipulation(t *testing.T) {
+	base := MustParseColour("#33065d")
+	if got := base.Tint(0.6).String(); got != "#ad9bbe" {
+		t.Errorf("Tint: got %s", got)
+	}
+	if got := base.Mix(White, 0).String(); got != "#33065d" {
+		t.Errorf("Mix(0): got %s", got)
+	}
+	if got := MustParseColour("red").Lighten(0.25).String(); got != "#ff8080" {
+		t.Errorf("Lighten: got %s", got)
+	}
+	if got := MustParseColour("red").Darken(0.25).String(); got != "#800000" {
+		t.Errorf("Darken: got %s", got)
+	}
+	if got := base.WithAlpha(0.5).Hex(); got != "#33065d80" {
+		t.Errorf("WithAlpha: got %s", got)
+	}
+	if got := ContrastRatio(Black, White); math.Abs(got-21) > 1e-9 {
+		t.Errorf("ContrastRatio: got %f", got)
+	}
+	if got := ReadableOn(MustParseColour("yellow")); got != Black {
+		t.Errorf("ReadableOn: got %s", got)
+	}
+}
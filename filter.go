@@ -0,0 +1,103 @@
+package visualisations
+
+import (
+	"fmt"
+	"strings"
+)
+
+type FilterKind string
+
+const (
+	FilterDropShadow = FilterKind("drop-shadow")
+	FilterGlow       = FilterKind("glow")
+	FilterBlur       = FilterKind("blur")
+)
+
+// Filter is an effect such as a drop shadow applied to a shape. svg
+// canvases define each filter once in <defs> and refer to it by a
+// generated id, other canvases draw the shape without it
+type Filter struct {
+	Kind FilterKind
+	// Radius is how far the shadow, glow or blur spreads in pixels,
+	// defaults to 2
+	Radius float64
+	// DX and DY offset a drop shadow from its shape. when both are zero
+	// the shadow falls 2 pixels down and to the right
+	DX, DY float64
+	// Colour of a shadow or glow. shadows default to black and glows to
+	// the colours of the shape
+	Colour string
+	// Opacity of a shadow or glow, defaults to 0.5 for shadows and 1 for
+	// glows
+	Opacity float64
+}
+
+// Validate reports every option that would stop the filter from being
+// drawn correctly
+func (f Filter) Validate() error {
+	v := &ValidationError{}
+	switch f.Kind {
+	case FilterDropShadow, FilterGlow, FilterBlur:
+	default:
+		v.Add("Kind", f.Kind, "must be drop-shadow, glow or blur")
+	}
+	v.NonNegative("Radius", f.Radius)
+	v.Between("Opacity", f.Opacity, 0, 1)
+	return v.Err()
+}
+
+func (f Filter) withDefaults() Filter {
+	if f.Radius == 0 {
+		f.Radius = 2
+	}
+	if f.Kind == FilterDropShadow {
+		if f.DX == 0 && f.DY == 0 {
+			f.DX, f.DY = 2, 2
+		}
+		if f.Colour == "" {
+			f.Colour = "black"
+		}
+	}
+	if f.Opacity == 0 {
+		f.Opacity = 1
+		if f.Kind == FilterDropShadow {
+			f.Opacity = 0.5
+		}
+	}
+	return f
+}
+
+// definition returns the svg element defining the filter, and the id
+// it is given there
+func (f Filter) definition() (def, id string) {
+	f = f.withDefaults()
+	var b strings.Builder
+	// the filter region is grown so the effect isn't clipped to the
+	// shape's bounding box
+	b.WriteString(`<filter x="-50%" y="-50%" width="200%" height="200%">` + "\n")
+	radius := number(f.Radius)
+	if f.Kind == FilterBlur {
+		fmt.Fprintf(&b, `<feGaussianBlur stdDeviation="%s" />`+"\n", radius)
+		b.WriteString("</filter>\n")
+		return withID(b.String())
+	}
+	in := "SourceAlpha"
+	if f.Colour == "" {
+		in = "SourceGraphic"
+	}
+	fmt.Fprintf(&b, `<feGaussianBlur in="%s" stdDeviation="%s" result="blur" />`+"\n", in, radius)
+	if f.Kind == FilterDropShadow {
+		fmt.Fprintf(&b, `<feOffset dx="%s" dy="%s" result="blur" />`+"\n", number(f.DX), number(f.DY))
+	}
+	if f.Colour != "" {
+		fmt.Fprintf(&b, `<feFlood flood-color="%s" flood-opacity="%s" />`+"\n",
+			escape(f.Colour), number(f.Opacity))
+		b.WriteString(`<feComposite in2="blur" operator="in" />` + "\n")
+	} else {
+		fmt.Fprintf(&b, `<feComponentTransfer><feFuncA type="linear" slope="%s" /></feComponentTransfer>`+"\n",
+			number(f.Opacity))
+	}
+	b.WriteString("<feMerge><feMergeNode /><feMergeNode in=\"SourceGraphic\" /></feMerge>\n")
+	b.WriteString("</filter>\n")
+	return withID(b.String())
+}
@@ -0,0 +1,87 @@
+package visualisations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterDefinition(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{
+			name:   "blur",
+			filter: Filter{Kind: FilterBlur, Radius: 1.5},
+			want: `<filter id="ID" x="-50%" y="-50%" width="200%" height="200%">
+<feGaussianBlur stdDeviation="1.5" />
+</filter>
+`,
+		},
+		{
+			name:   "drop shadow",
+			filter: Filter{Kind: FilterDropShadow},
+			want: `<filter id="ID" x="-50%" y="-50%" width="200%" height="200%">
+<feGaussianBlur in="SourceAlpha" stdDeviation="2" result="blur" />
+<feOffset dx="2" dy="2" result="blur" />
+<feFlood flood-color="black" flood-opacity="0.5" />
+<feComposite in2="blur" operator="in" />
+<feMerge><feMergeNode /><feMergeNode in="SourceGraphic" /></feMerge>
+</filter>
+`,
+		},
+		{
+			name:   "coloured glow",
+			filter: Filter{Kind: FilterGlow, Radius: 3, Colour: "gold", Opacity: 0.8},
+			want: `<filter id="ID" x="-50%" y="-50%" width="200%" height="200%">
+<feGaussianBlur in="SourceAlpha" stdDeviation="3" result="blur" />
+<feFlood flood-color="gold" flood-opacity="0.8" />
+<feComposite in2="blur" operator="in" />
+<feMerge><feMergeNode /><feMergeNode in="SourceGraphic" /></feMerge>
+</filter>
+`,
+		},
+		{
+			name:   "glow",
+			filter: Filter{Kind: FilterGlow},
+			want: `<filter id="ID" x="-50%" y="-50%" width="200%" height="200%">
+<feGaussianBlur in="SourceGraphic" stdDeviation="2" result="blur" />
+<feComponentTransfer><feFuncA type="linear" slope="1" /></feComponentTransfer>
+<feMerge><feMergeNode /><feMergeNode in="SourceGraphic" /></feMerge>
+</filter>
+`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			def, id := testcase.filter.definition()
+			if got := strings.Replace(def, id, "ID", 1); got != testcase.want {
+				t.Errorf("got\n%s\nwant\n%s", got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	for _, testcase := range []struct {
+		filter Filter
+		want   string
+	}{
+		{filter: Filter{Kind: FilterBlur}},
+		{filter: Filter{Kind: "sparkle"}, want: "Kind"},
+		{filter: Filter{Kind: FilterGlow, Radius: -1}, want: "Radius"},
+		{filter: Filter{Kind: FilterDropShadow, Opacity: 2}, want: "Opacity"},
+	} {
+		err := testcase.filter.Validate()
+		if testcase.want == "" {
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", testcase.filter, err)
+			}
+			continue
+		}
+		ve, ok := err.(*ValidationError)
+		if !ok || ve.Errors[0].Field != testcase.want {
+			t.Errorf("%v: got %v, want an error for %s", testcase.filter, err, testcase.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package visualisations
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Font is a font file to embed in svg documents, so that they look the
+// same whether or not the viewer has the font installed. see SVG.Fonts
+type Font struct {
+	// Family is the name styles refer to the font by, as the first
+	// family of their FontFamily. it can't hold quotes, backslashes,
+	// the characters xml escapes or control characters
+	Family string
+	data   []byte
+	// mime is the media type of the font in a data uri, and format its
+	// format in css
+	mime, format string
+}
+
+// ParseFont reads a TrueType, OpenType or WOFF2 font file to embed as
+// `family`. only TrueType fonts can be subsetted, as OpenType outlines
+// are in a different format and WOFF2 is compressed with brotli, which
+// the standard library can't read. other fonts are embedded whole
+func ParseFont(family string, data []byte) (*Font, error) {
+	if err := checkFamily(family); err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errFontTruncated
+	}
+	f := &Font{Family: family, data: data}
+	switch string(data[:4]) {
+	case "\x00\x01\x00\x00", "true":
+		f.mime, f.format = "font/ttf", "truetype"
+		// check the tables subsetting needs up front
+		if _, err := subsetTrueType(data, nil); err != nil {
+			return nil, err
+		}
+	case "OTTO":
+		f.mime, f.format = "font/otf", "opentype"
+		if _, err := parseTables(data); err != nil {
+			return nil, err
+		}
+	case "wOF2":
+		f.mime, f.format = "font/woff2", "woff2"
+		if len(data) < 48 || binary.BigEndian.Uint32(data[8:]) != uint32(len(data)) {
+			return nil, errFontTruncated
+		}
+	default:
+		return nil, errors.New("visualisations: font: not a TrueType, OpenType or WOFF2 file")
+	}
+	return f, nil
+}
+
+// Subsettable reports whether the font is subsetted when embedded
+func (f *Font) Subsettable() bool {
+	return f.format == "truetype"
+}
+
+// face returns the css @font-face rule for the font with only the
+// glyphs needed for `text`
+func (f *Font) face(text string) (string, error) {
+	if err := checkFamily(f.Family); err != nil {
+		return "", err
+	}
+	data := f.data
+	if f.Subsettable() {
+		var err error
+		if data, err = subsetTrueType(f.data, runesOf(text)); err != nil {
+			return "", err
+		}
+	}
+	return "@font-face{font-family:'" + f.Family + "';" +
+		"src:url(data:" + f.mime + ";base64," + base64.StdEncoding.EncodeToString(data) + ") format('" + f.format + "')}\n", nil
+}
+
+// checkFamily rejects font families that could end the css string or
+// the style element they are written into
+func checkFamily(family string) error {
+	if family == "" {
+		return errors.New("visualisations: font: family must be set")
+	}
+	for _, r := range family {
+		if strings.ContainsRune(`'"\<>&`, r) || unicode.IsControl(r) {
+			return fmt.Errorf("visualisations: font: family %q can't contain %q", family, r)
+		}
+	}
+	return nil
+}
+
+// runesOf returns the distinct runes of `text` in order
+func runesOf(text string) []rune {
+	seen := map[rune]bool{}
+	var runes []rune
+	for _, r := range text {
+		if !seen[r] {
+			seen[r] = true
+			runes = append(runes, r)
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
+
+// firstFamily returns the first family of a css font-family, unquoted
+func firstFamily(fontFamily string) string {
+	family := strings.Split(fontFamily, ",")[0]
+	return strings.Trim(strings.TrimSpace(family), `"'`)
+}
@@ -0,0 +1,177 @@
+package visualisations
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testFont builds a TrueType font with glyphs for "A", "B" and "Ä",
+// which is made of the glyphs for "A" and a dieresis
+func testFont() []byte {
+	simple := func(marker byte) []byte {
+		// one contour, a bounding box, then stand in outline data
+		return []byte{0, 1, 0, 0, 0, 0, 0, 9, 0, 9, marker, marker}
+	}
+	composite := []byte{0xff, 0xff, 0, 0, 0, 0, 0, 9, 0, 9,
+		0, 0x22, 0, 1, 0, 0, // more components, glyph 1
+		0, 0x02, 0, 4, 0, 5, // glyph 4
+	}
+	glyphs := [][]byte{simple('.'), simple('A'), simple('B'), composite, simple(':')}
+	var glyf []byte
+	loca := make([]byte, 2*(len(glyphs)+1))
+	for i, g := range glyphs {
+		glyf = append(glyf, g...)
+		for len(glyf)%2 != 0 {
+			glyf = append(glyf, 0)
+		}
+		binary.BigEndian.PutUint16(loca[2*i+2:], uint16(len(glyf)/2))
+	}
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head, 0x00010000)
+	binary.BigEndian.PutUint32(head[12:], 0x5f0f3cf5)
+	maxp := make([]byte, 32)
+	binary.BigEndian.PutUint32(maxp, 0x00010000)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(len(glyphs)))
+	return writeTables(0x00010000, map[string][]byte{
+		"cmap": buildCmap(map[rune]uint16{'A': 1, 'B': 2, 'Ä': 3}),
+		"head": head,
+		"hhea": make([]byte, 36),
+		"hmtx": make([]byte, 4*len(glyphs)),
+		"maxp": maxp,
+		"loca": loca,
+		"glyf": glyf,
+		"GSUB": []byte("ligatures"),
+	})
+}
+
+func TestSubsetTrueType(t *testing.T) {
+	font := testFont()
+	subset, err := subsetTrueType(font, []rune("Äx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := checksum(subset); sum != 0xb1b0afba {
+		t.Errorf("font checksum is %x", sum)
+	}
+	tables, err := parseTables(subset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tables["GSUB"] != nil {
+		t.Error("layout tables were kept")
+	}
+	for r, want := range map[rune]uint16{'Ä': 3, 'A': 0, 'B': 0, 'x': 0} {
+		if id, _ := lookupGlyph(tables["cmap"], r); id != want {
+			t.Errorf("%q is glyph %d, want %d", r, id, want)
+		}
+	}
+	glyf := tables["glyf"]
+	for _, want := range []string{"..", "AA", "::"} {
+		if !bytes.Contains(glyf, []byte(want)) {
+			t.Errorf("glyph %q was dropped", want[:1])
+		}
+	}
+	if bytes.Contains(glyf, []byte("BB")) {
+		t.Error("unused glyph B was kept")
+	}
+}
+
+func TestParseFont(t *testing.T) {
+	woff2 := make([]byte, 48)
+	copy(woff2, "wOF2")
+	binary.BigEndian.PutUint32(woff2[8:], 48)
+	for _, testcase := range []struct {
+		name       string
+		family     string
+		data       []byte
+		subsetting bool
+		err        bool
+	}{
+		{name: "truetype", family: "Test", data: testFont(), subsetting: true},
+		{name: "woff2", family: "Test", data: woff2},
+		{name: "no family", data: testFont(), err: true},
+		{name: "hostile family", family: "x'}</style><script>alert(1)</script>", data: testFont(), err: true},
+		{name: "backslash family", family: `x\`, data: testFont(), err: true},
+		{name: "newline family", family: "x\ny", data: testFont(), err: true},
+		{name: "truncated", family: "Test", data: testFont()[:40], err: true},
+		{name: "unknown", family: "Test", data: []byte("GIF89a"), err: true},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			f, err := ParseFont(testcase.family, testcase.data)
+			if testcase.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Subsettable() != testcase.subsetting {
+				t.Errorf("Subsettable() = %v", f.Subsettable())
+			}
+		})
+	}
+}
+
+func TestSVGHostileFamily(t *testing.T) {
+	f, err := ParseFont("Test", testFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the family can still be changed after parsing
+	f.Family = "Test'}</style><script>"
+	s := NewSVG(&strings.Builder{})
+	s.Fonts = []*Font{f}
+	err = DrawTo(s, drawing(func(c Canvas) {
+		c.Text(0, 0, "A", Style{FontFamily: f.Family})
+	}))
+	if err == nil {
+		t.Error("expected an error for the hostile family")
+	}
+}
+
+func TestSVGFonts(t *testing.T) {
+	used, err := ParseFont("Test Font", testFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	unused, err := ParseFont("Unused", testFont())
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	s := NewSVG(builder)
+	s.Fonts = []*Font{used, unused}
+	err = DrawTo(s, drawing(func(c Canvas) {
+		c.Text(0, 0, "AÄ", Style{FontFamily: "'test font', sans-serif"})
+		c.Text(0, 0, "B", Style{FontFamily: "sans-serif"})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := builder.String()
+	faces := regexp.MustCompile(`@font-face\{font-family:'([^']+)';src:url\(data:font/ttf;base64,([^)]+)\) format\('truetype'\)\}`).
+		FindAllStringSubmatch(got, -1)
+	if len(faces) != 1 || faces[0][1] != "Test Font" {
+		t.Fatalf("want one font face for Test Font:\n%s", got)
+	}
+	data, err := base64.StdEncoding.DecodeString(faces[0][2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := parseTables(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := lookupGlyph(tables["cmap"], 'B'); id != 0 {
+		t.Errorf("B drawn in another font was embedded")
+	}
+	if id, _ := lookupGlyph(tables["cmap"], 'A'); id != 1 {
+		t.Errorf("A was not embedded")
+	}
+}
@@ -0,0 +1,300 @@
+// Package format turns numbers into labels, such as 1.2k, 3.4 MiB, 42%
+// or 1h 5m, so that every visualisation formats values the same way
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Formatter turns a value into its label. it can be used for any option
+// taking a func(float64) string, such as the Format of an axis
+type Formatter func(float64) string
+
+// Suffix returns a formatter that appends `unit` to the labels of `f`,
+// such as " req/s"
+func (f Formatter) Suffix(unit string) Formatter {
+	return func(v float64) string {
+		return f(v) + unit
+	}
+}
+
+// Locale holds the separators numbers are written with
+type Locale struct {
+	// Decimal separates the whole and fractional parts, defaults to "."
+	Decimal string
+	// Thousands separates groups of three digits in the whole part, no
+	// separator is written when it is empty
+	Thousands string
+}
+
+var (
+	// Default doesn't group thousands, so it suits any language
+	Default = Locale{Decimal: "."}
+	English = Locale{Decimal: ".", Thousands: ","}
+	German  = Locale{Decimal: ",", Thousands: "."}
+	// French groups thousands with a narrow no-break space
+	French = Locale{Decimal: ",", Thousands: "\u202f"}
+	Swiss  = Locale{Decimal: ".", Thousands: "’"}
+)
+
+// siPrefixes are the metric prefixes from yocto to yotta
+var siPrefixes = []string{
+	"y", "z", "a", "f", "p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E", "Z", "Y",
+}
+
+var (
+	decimalBytes = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	binaryBytes  = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+// durationUnits are the units of a duration in seconds, largest first
+var durationUnits = []struct {
+	name    string
+	seconds float64
+}{
+	{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1},
+}
+
+// Fixed writes values with `decimals` decimal places, such as 1234.50
+func (l Locale) Fixed(decimals int) Formatter {
+	return func(v float64) string {
+		if s, ok := special(v); ok {
+			return s
+		}
+		return l.number(strconv.FormatFloat(v, 'f', decimals, 64))
+	}
+}
+
+// Significant writes values rounded to `digits` significant digits,
+// without trailing zeros, such as 1230 or 0.0456
+func (l Locale) Significant(digits int) Formatter {
+	return func(v float64) string {
+		if s, ok := special(v); ok {
+			return s
+		}
+		return l.number(trimZeros(significant(v, digits)))
+	}
+}
+
+// Percent writes a proportion, where 1 is 100%, with `decimals` decimal
+// places, such as 42.5%
+func (l Locale) Percent(decimals int) Formatter {
+	fixed := l.Fixed(decimals)
+	return func(v float64) string {
+		return fixed(v*100) + "%"
+	}
+}
+
+// SI writes values to `digits` significant digits with a metric prefix,
+// such as 1.2k, 3.4M or 5µ
+func (l Locale) SI(digits int) Formatter {
+	return func(v float64) string {
+		if s, ok := special(v); ok {
+			return s
+		}
+		r := round(v, digits)
+		if r == 0 {
+			return "0"
+		}
+		p := int(math.Floor(math.Log10(math.Abs(r)) / 3))
+		p = int(math.Max(-8, math.Min(8, float64(p))))
+		scaled := r / math.Pow(1000, float64(p))
+		return l.number(trimZeros(significant(scaled, digits))) + siPrefixes[p+8]
+	}
+}
+
+// Bytes writes a number of bytes to `digits` significant digits in
+// powers of 1000, such as 512 B or 1.5 MB
+func (l Locale) Bytes(digits int) Formatter {
+	return l.bytes(digits, 1000, decimalBytes)
+}
+
+// BinaryBytes writes a number of bytes to `digits` significant digits
+// in powers of 1024, such as 512 B or 1.5 MiB
+func (l Locale) BinaryBytes(digits int) Formatter {
+	return l.bytes(digits, 1024, binaryBytes)
+}
+
+func (l Locale) bytes(digits int, base float64, units []string) Formatter {
+	return func(v float64) string {
+		if s, ok := special(v); ok {
+			return s
+		}
+		i := 0
+		for math.Abs(v) >= base && i < len(units)-1 {
+			v /= base
+			i++
+		}
+		// rounding can carry into the next unit, as with 999.96 kB
+		if math.Abs(round(v, digits)) >= base && i < len(units)-1 {
+			v /= base
+			i++
+		}
+		return l.number(trimZeros(significant(v, digits))) + " " + units[i]
+	}
+}
+
+// Duration writes a number of seconds with at most `units` of days,
+// hours, minutes and seconds, such as 1h 5m. the last unit is rounded,
+// and durations under a minute are written to three significant digits
+// in seconds or fractions of a second, such as 1.5s or 250ms
+func (l Locale) Duration(units int) Formatter {
+	if units < 1 {
+		units = 1
+	}
+	return func(v float64) string {
+		if s, ok := special(v); ok {
+			return s
+		}
+		sign := ""
+		if v < 0 {
+			sign, v = "-", -v
+		}
+		switch {
+		case v == 0:
+			return "0s"
+		case round(v, 3) < 1e-6:
+			return sign + l.Significant(3)(v*1e9) + "ns"
+		case round(v, 3) < 1e-3:
+			return sign + l.Significant(3)(v*1e6) + "µs"
+		case round(v, 3) < 1:
+			return sign + l.Significant(3)(v*1e3) + "ms"
+		case round(v, 3) < 60:
+			return sign + l.Significant(3)(v) + "s"
+		}
+		largest := 0
+		for durationUnits[largest].seconds > v {
+			largest++
+		}
+		smallest := largest + units - 1
+		if smallest >= len(durationUnits) {
+			smallest = len(durationUnits) - 1
+		}
+		step := durationUnits[smallest].seconds
+		v = math.Round(v/step) * step
+		var parts []string
+		for _, unit := range durationUnits[:smallest+1] {
+			n := math.Floor(v / unit.seconds)
+			v -= n * unit.seconds
+			if n > 0 {
+				parts = append(parts, l.Fixed(0)(n)+unit.name)
+			}
+		}
+		return sign + strings.Join(parts, " ")
+	}
+}
+
+// Fixed writes values with `decimals` decimal places in the Default
+// locale
+func Fixed(decimals int) Formatter {
+	return Default.Fixed(decimals)
+}
+
+// Significant writes values to `digits` significant digits in the
+// Default locale
+func Significant(digits int) Formatter {
+	return Default.Significant(digits)
+}
+
+// Percent writes proportions as percentages in the Default locale
+func Percent(decimals int) Formatter {
+	return Default.Percent(decimals)
+}
+
+// SI writes values with a metric prefix in the Default locale
+func SI(digits int) Formatter {
+	return Default.SI(digits)
+}
+
+// Bytes writes numbers of bytes in powers of 1000 in the Default locale
+func Bytes(digits int) Formatter {
+	return Default.Bytes(digits)
+}
+
+// BinaryBytes writes numbers of bytes in powers of 1024 in the Default
+// locale
+func BinaryBytes(digits int) Formatter {
+	return Default.BinaryBytes(digits)
+}
+
+// Duration writes numbers of seconds in the Default locale
+func Duration(units int) Formatter {
+	return Default.Duration(units)
+}
+
+// special writes the values that aren't numbers
+func special(v float64) (string, bool) {
+	switch {
+	case math.IsNaN(v):
+		return "NaN", true
+	case math.IsInf(v, 1):
+		return "∞", true
+	case math.IsInf(v, -1):
+		return "-∞", true
+	}
+	return "", false
+}
+
+// round rounds `v` to `digits` significant digits
+func round(v float64, digits int) float64 {
+	if v == 0 {
+		return 0
+	}
+	if digits < 1 {
+		digits = 1
+	}
+	scale := math.Pow(10, float64(digits-1)-math.Floor(math.Log10(math.Abs(v))))
+	return math.Round(v*scale) / scale
+}
+
+// significant writes `v` rounded to `digits` significant digits
+func significant(v float64, digits int) string {
+	v = round(v, digits)
+	if v == 0 {
+		return "0"
+	}
+	decimals := digits - 1 - int(math.Floor(math.Log10(math.Abs(v))))
+	if decimals < 0 {
+		decimals = 0
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// trimZeros removes the zeros that end the fractional part of `s`
+func trimZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// number writes a number formatted by strconv with the separators of
+// the locale. negative numbers that round to zero lose their sign
+func (l Locale) number(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if strings.Trim(s, "0.") == "" {
+		sign = ""
+	}
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if l.Thousands != "" {
+		for i := len(whole) - 3; i > 0; i -= 3 {
+			whole = whole[:i] + l.Thousands + whole[i:]
+		}
+	}
+	if fraction != "" {
+		decimal := l.Decimal
+		if decimal == "" {
+			decimal = "."
+		}
+		whole += decimal + fraction
+	}
+	return sign + whole
+}
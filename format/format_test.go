@@ -0,0 +1,59 @@
+package format
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatters(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		format Formatter
+		value  float64
+		want   string
+	}{
+		{name: "fixed", format: Fixed(2), value: 1234.5, want: "1234.50"},
+		{name: "fixed negative zero", format: Fixed(1), value: -0.01, want: "0.0"},
+		{name: "fixed grouped", format: English.Fixed(0), value: -1234567, want: "-1,234,567"},
+		{name: "fixed german", format: German.Fixed(2), value: 1234.5, want: "1.234,50"},
+		{name: "fixed french", format: French.Fixed(1), value: 12345.67, want: "12 345,7"},
+		{name: "significant", format: Significant(3), value: 1234.5, want: "1230"},
+		{name: "significant small", format: Significant(3), value: 0.045678, want: "0.0457"},
+		{name: "significant trimmed", format: Significant(4), value: 2.5, want: "2.5"},
+		{name: "significant zero", format: Significant(3), value: 0, want: "0"},
+		{name: "percent", format: Percent(1), value: 0.425, want: "42.5%"},
+		{name: "percent german", format: German.Percent(0), value: 12.5, want: "1.250%"},
+		{name: "si kilo", format: SI(2), value: 1234, want: "1.2k"},
+		{name: "si mega", format: SI(2), value: 3.4e6, want: "3.4M"},
+		{name: "si micro", format: SI(3), value: 0.0000052, want: "5.2µ"},
+		{name: "si unit", format: SI(3), value: 42, want: "42"},
+		{name: "si carry", format: SI(3), value: 999.96, want: "1k"},
+		{name: "si negative", format: SI(2), value: -1500, want: "-1.5k"},
+		{name: "si german", format: German.SI(2), value: 1234, want: "1,2k"},
+		{name: "bytes", format: Bytes(3), value: 512, want: "512 B"},
+		{name: "bytes mega", format: Bytes(2), value: 1.5e6, want: "1.5 MB"},
+		{name: "bytes carry", format: Bytes(3), value: 999960, want: "1 MB"},
+		{name: "binary bytes", format: BinaryBytes(3), value: 1536, want: "1.5 KiB"},
+		{name: "binary bytes gibi", format: BinaryBytes(3), value: 3 << 30, want: "3 GiB"},
+		{name: "duration zero", format: Duration(2), value: 0, want: "0s"},
+		{name: "duration ns", format: Duration(2), value: 2.5e-8, want: "25ns"},
+		{name: "duration µs", format: Duration(2), value: 0.0000125, want: "12.5µs"},
+		{name: "duration ms", format: Duration(2), value: 0.25, want: "250ms"},
+		{name: "duration seconds", format: Duration(2), value: 1.5, want: "1.5s"},
+		{name: "duration minutes", format: Duration(2), value: 150, want: "2m 30s"},
+		{name: "duration hours", format: Duration(2), value: 3900, want: "1h 5m"},
+		{name: "duration rounded", format: Duration(2), value: 7199, want: "2h"},
+		{name: "duration days", format: Duration(3), value: 3*86400 + 4*3600 + 59, want: "3d 4h 1m"},
+		{name: "duration one unit", format: Duration(1), value: 5400, want: "2h"},
+		{name: "duration negative", format: Duration(2), value: -90, want: "-1m 30s"},
+		{name: "nan", format: SI(3), value: math.NaN(), want: "NaN"},
+		{name: "infinity", format: Fixed(2), value: math.Inf(-1), want: "-∞"},
+		{name: "suffix", format: SI(3).Suffix(" req/s"), value: 42000, want: "42k req/s"},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if got := testcase.format(testcase.value); got != testcase.want {
+				t.Errorf("got %q, want %q", got, testcase.want)
+			}
+		})
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strings"
 
-	svg "github.com/ajstarks/svgo"
 	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/measure"
+	"github.com/osraige/visualisations/scale"
 )
 
 type GaugeOptions struct {
-	canvas *svg.SVG
+	canvas visual.Canvas
 
 	// Size is determines the width and height of the gauge
 	Size float64
@@ -21,95 +23,343 @@ type GaugeOptions struct {
 	// BackgroundColour sets the non filled portion of the gauge's colour
 	BackgroundColour string
 	// Colour is the colour to fill the gauge with
-	Colour    string
-	LineWidth float64
+	Colour string
+	// Paint and BackgroundPaint draw the filled and non filled portions
+	// with a gradient or pattern instead of their colours, on canvases
+	// that support it. the colours default to the closest flat colour
+	Paint           *visual.Paint
+	BackgroundPaint *visual.Paint
+	// ArcFilter and LabelFilter apply effects such as a drop shadow to
+	// the arcs and label, on canvases that support them
+	ArcFilter   *visual.Filter
+	LabelFilter *visual.Filter
+	LineWidth   float64
 	// FillPorportion is the proportion of the gauge to fill between 0 and 1
 	FillProportion float64
+	// Value is mapped through Scale to set FillProportion, when Scale
+	// is given. the scale's range should be 0 to 1
+	Value float64
+	Scale scale.Continuous
 
 	// Label is the text to display in the center of the gauge
-	Label       string
+	Label string
+	// LabelFormat writes the label when Label is empty, from Value when
+	// there is a Scale and otherwise from FillProportion, such as
+	// format.Percent(0)
+	LabelFormat func(float64) string
 	LabelFont   string
 	LabelColour string
 	LabelSize   int
+	// FitLabel shrinks the label below LabelSize when it would otherwise
+	// overlap the ring
+	FitLabel bool
+
+	// Theme provides defaults for any of the above options left unset
+	Theme *visual.Theme
+	// DarkTheme recolours what was drawn in Theme's colours when the
+	// viewer prefers a dark colour scheme, on canvases that support it
+	DarkTheme *visual.Theme
+	// Accessibility sets the title and description read out by screen
+	// readers, they default to a summary of the fill and label
+	Accessibility visual.Accessibility
+}
+
+func (g *GaugeOptions) applyTheme() {
+	if g.Theme == nil {
+		return
+	}
+	if g.Colour == "" {
+		g.Colour = g.Theme.Primary.String()
+	}
+	if g.BackgroundColour == "" {
+		g.BackgroundColour = g.Theme.Track().String()
+	}
+	if g.LineWidth == 0 {
+		g.LineWidth = g.Theme.LineWidth
+	}
+	if g.LabelFont == "" {
+		g.LabelFont = g.Theme.FontFamily
+	}
+	if g.LabelColour == "" {
+		g.LabelColour = g.Theme.TextColour.String()
+	}
+	if g.LabelSize == 0 {
+		g.LabelSize = g.Theme.FontSize
+	}
+}
+
+// Validate reports every option that would stop the gauge from being
+// drawn correctly
+func (g GaugeOptions) Validate() error {
+	v := &visual.ValidationError{}
+	v.Positive("Size", g.Size)
+	v.NonNegative("Padding", g.Padding)
+	v.Check(g.Padding < g.Size/2, "Padding", g.Padding,
+		"must be less than half of Size")
+	v.Check(g.GapRadians >= 0 && g.GapRadians < 2*math.Pi,
+		"GapRadians", g.GapRadians, "must be at least 0 and less than 2π")
+	v.NonNegative("LineWidth", g.LineWidth)
+	if g.Scale != nil {
+		v.Check(inUnit(g.Scale.Map(g.Value)), "Value", g.Value,
+			"must be mapped by Scale to between 0 and 1")
+	} else {
+		v.Between("FillProportion", g.FillProportion, 0, 1)
+	}
+	v.NonNegative("LabelSize", float64(g.LabelSize))
+	if g.Paint != nil {
+		v.Nest("Paint", g.Paint.Validate())
+	}
+	if g.BackgroundPaint != nil {
+		v.Nest("BackgroundPaint", g.BackgroundPaint.Validate())
+	}
+	if g.ArcFilter != nil {
+		v.Nest("ArcFilter", g.ArcFilter.Validate())
+	}
+	if g.LabelFilter != nil {
+		v.Nest("LabelFilter", g.LabelFilter.Validate())
+	}
+	return v.Err()
+}
+
+// Resolved returns the options with the theme applied and, when there
+// is a Scale, FillProportion set from Value
+func (g GaugeOptions) Resolved() GaugeOptions {
+	if g.Colour == "" {
+		g.Colour = g.Paint.Colour()
+	}
+	if g.BackgroundColour == "" {
+		g.BackgroundColour = g.BackgroundPaint.Colour()
+	}
+	g.applyTheme()
+	if g.Scale != nil {
+		g.FillProportion = g.Scale.Map(g.Value)
+	}
+	if g.Label == "" && g.LabelFormat != nil {
+		if g.Scale != nil {
+			g.Label = g.LabelFormat(g.Value)
+		} else {
+			g.Label = g.LabelFormat(g.FillProportion)
+		}
+	}
+	return g
 }
 
 func (g *GaugeOptions) drawGauge() {
+	*g = g.Resolved()
+	if g.Theme != nil && g.Theme.HasBackground() {
+		g.canvas.Rect(0, 0, g.Size, g.Size, g.Theme.BackgroundStyle())
+	}
 	c := g.Size / 2
 	r := c - g.Padding
-	//
-	startAngle := math.Pi + (math.Pi-g.GapRadians)/2
-	startAngle = math.Mod(startAngle, 2*math.Pi)
-	startX := int(c + r*math.Cos(startAngle))
-	startY := int(c - r*math.Sin(startAngle))
-	//
-	endAngle := startAngle + g.GapRadians
-	endAngle = math.Mod(endAngle, 2*math.Pi)
-	endX := int(c + r*math.Cos(endAngle))
-	endY := int(c - r*math.Sin(endAngle))
-	//
-	midAngle := startAngle - g.FillProportion*
-		(math.Pi*2-(endAngle-startAngle))
-	midX := int(c + r*math.Cos(midAngle))
-	midY := int(c - r*math.Sin(midAngle))
-	//
-	large := math.Pi < startAngle-midAngle
-	g.canvas.Arc(startX, startY, int(r), int(r), 0, large, true, midX, midY,
-		visual.ParseStyles(
-			visual.ParseStroke(g.Colour),
-			visual.ParseStrokeWidth(g.LineWidth),
-			visual.ParseFill("none"),
-		))
-	//
-	large = math.Pi > endAngle-midAngle
-	g.canvas.Arc(midX, midY, int(r), int(r), 0, large, true, endX, endY,
-		visual.ParseStyles(
-			visual.ParseStroke(g.BackgroundColour),
-			visual.ParseStrokeWidth(g.LineWidth),
-			visual.ParseFill("none"),
-		))
-
-	g.canvas.Text(int(c), int(c), g.Label,
-		visual.ParseStyles(
-			visual.ParseFill(g.LabelColour),
-			visual.ParseFontSize(g.LabelSize),
-			visual.ParseDominantBaseline("central"),
-			visual.ParseTextAnchor("middle"),
-			visual.ParseFontFamily(g.LabelFont),
-		))
-}
-
-// Gauge generates a gauge with the given options
-func Gauge(out io.Writer, opts GaugeOptions) {
-	canvas := svg.New(out)
-	canvas.Start(int(opts.Size), int(opts.Size))
-	defer canvas.End()
-	opts.canvas = canvas
-	canvas.Gid("root")
-	opts.drawGauge()
-	canvas.Gend()
-}
-
-// Gauges generates a single image with several gauges joined horizontaly
-func Gauges(out io.Writer, opts []GaugeOptions) {
+	// angles are in degrees clockwise from pointing right, the gauge
+	// starts after the gap at the bottom and fills clockwise
+	gap := g.GapRadians * 180 / math.Pi
+	startAngle := 90 + gap/2
+	endAngle := startAngle + 360 - gap
+	midAngle := startAngle + g.FillProportion*(endAngle-startAngle)
+	arcStyle := visual.Style{
+		StrokeWidth: visual.Float(g.LineWidth),
+		Fill:        "none",
+		Filter:      g.ArcFilter,
+	}
+	fill := visual.NewPath().Arc(c, c, r, startAngle, midAngle)
+	g.arc(fill, arcStyle.Override(visual.Style{
+		Stroke:      g.Colour,
+		StrokePaint: g.Paint,
+		Class:       "gauge-fill",
+	}),
+		visual.Percent(g.FillProportion)+" filled")
+	track := visual.NewPath().Arc(c, c, r, midAngle, endAngle)
+	g.arc(track, arcStyle.Override(visual.Style{
+		Stroke:      g.BackgroundColour,
+		StrokePaint: g.BackgroundPaint,
+		Class:       "gauge-track",
+	}),
+		visual.Percent(1-g.FillProportion)+" remaining")
+
+	labelSize := g.LabelSize
+	if g.FitLabel {
+		// leave a margin of a line width either side inside the ring
+		inner := 2 * (r - g.LineWidth*1.5)
+		labelSize = measure.FitSize(g.Label, inner, g.LabelFont, g.LabelSize)
+	}
+	g.canvas.Text(c, c, g.Label,
+		visual.Style{
+			Fill:             g.LabelColour,
+			FontSize:         visual.Int(labelSize),
+			DominantBaseline: "central",
+			TextAnchor:       "middle",
+			FontFamily:       g.LabelFont,
+			Filter:           g.LabelFilter,
+			Class:            "gauge-label",
+		})
+}
+
+// arc draws one of the arcs of the gauge, grouped with a title unless
+// accessibility metadata is disabled
+func (g *GaugeOptions) arc(p *visual.Path, style visual.Style, title string) {
+	if g.Accessibility.Disabled {
+		g.canvas.Path(p, style)
+		return
+	}
+	g.canvas.Group("")
+	g.canvas.Title(title)
+	g.canvas.Path(p, style)
+	g.canvas.EndGroup()
+}
+
+// summary describes the fill and label of the gauge in words
+func (g GaugeOptions) summary() string {
+	g = g.Resolved()
+	s := "Gauge at " + visual.Percent(g.FillProportion)
+	if g.Label != "" {
+		s += " labelled " + g.Label
+	}
+	return s
+}
+
+// Describe summarises the gauge for screen readers
+func (g GaugeOptions) Describe() (title, desc string) {
+	g = g.Resolved()
+	title = "Gauge"
+	if g.Label != "" {
+		title = g.Label
+	}
+	return g.Accessibility.Describe(title, g.summary())
+}
+
+// DarkColours maps the colours of Theme to those of DarkTheme
+func (g GaugeOptions) DarkColours() visual.ColourMap {
+	if g.Theme == nil || g.DarkTheme == nil {
+		return nil
+	}
+	return g.Theme.DarkColours(*g.DarkTheme)
+}
+
+func inUnit(v float64) bool {
+	return v >= 0 && v <= 1
+}
+
+func init() {
+	visual.Register("gauge", func(decode visual.Decoder) (visual.Renderer, error) {
+		opts := GaugeOptions{}
+		err := decode(&opts)
+		return opts, err
+	})
+	visual.Register("gauges", func(decode visual.Decoder) (visual.Renderer, error) {
+		opts := GaugeSet{}
+		err := decode(&opts)
+		return opts, err
+	})
+}
+
+// Render draws the gauge as an svg
+func (g GaugeOptions) Render(out io.Writer) error {
+	return Gauge(out, g)
+}
+
+func (g GaugeOptions) PreferredSize() (float64, float64) {
+	return g.Size, g.Size
+}
+
+// GaugeSet is several gauges joined horizontally
+type GaugeSet []GaugeOptions
+
+// Render draws the gauges as a single svg
+func (s GaugeSet) Render(out io.Writer) error {
+	return Gauges(out, s)
+}
+
+// Validate reports the invalid options of every gauge in the set
+func (s GaugeSet) Validate() error {
+	v := &visual.ValidationError{}
+	for i, opt := range s {
+		v.Nest(fmt.Sprintf("[%d]", i), opt.Validate())
+	}
+	return v.Err()
+}
+
+func (s GaugeSet) PreferredSize() (float64, float64) {
 	var totalWidth float64
 	var maxHeight float64
-	for _, opt := range opts {
+	for _, opt := range s {
 		totalWidth += opt.Size
 		if maxHeight < opt.Size {
 			maxHeight = opt.Size
 		}
 	}
-	canvas := svg.New(out)
-	canvas.Start(int(totalWidth), int(maxHeight))
-	defer canvas.End()
-	curWidth := 0
-	canvas.Gid("root")
-	for _, opt := range opts {
-		opt.canvas = canvas
-		canvas.Gtransform(fmt.Sprintf("translate(%v)", curWidth))
-		curWidth += int(opt.Size)
+	return totalWidth, maxHeight
+}
+
+// Draw draws the gauge onto `c`
+func (g GaugeOptions) Draw(c visual.Canvas) {
+	g.canvas = c
+	c.Group("root")
+	g.drawGauge()
+	c.EndGroup()
+}
+
+// Describe summarises every gauge in the set for screen readers. the
+// set has no options of its own, so gauges with accessibility disabled
+// are left out
+func (s GaugeSet) Describe() (title, desc string) {
+	var summaries []string
+	for _, opt := range s {
+		if _, d := opt.Describe(); d != "" {
+			summaries = append(summaries, d)
+		}
+	}
+	switch len(summaries) {
+	case 0:
+		return "", ""
+	case 1:
+		title = "1 gauge"
+	default:
+		title = fmt.Sprintf("%d gauges", len(summaries))
+	}
+	return title, strings.Join(summaries, "; ")
+}
+
+// DarkColours combines the dark colours of every gauge in the set, the
+// first gauge to map a colour wins
+func (s GaugeSet) DarkColours() visual.ColourMap {
+	colours := visual.ColourMap{}
+	for _, opt := range s {
+		for light, dark := range opt.DarkColours() {
+			if _, ok := colours[light]; !ok {
+				colours[light] = dark
+			}
+		}
+	}
+	return colours
+}
+
+// Draw draws the gauges side by side onto `c`
+func (s GaugeSet) Draw(c visual.Canvas) {
+	curWidth := 0.0
+	c.Group("root")
+	for _, opt := range s {
+		opt.canvas = c
+		c.Translate(curWidth, 0)
+		if _, desc := opt.Describe(); desc != "" {
+			c.Title(desc)
+		}
+		curWidth += opt.Size
 		opt.drawGauge()
-		canvas.Gend()
+		c.EndGroup()
 	}
-	canvas.Gend()
+	c.EndGroup()
+}
+
+// Gauge generates a gauge with the given options. nothing is written
+// if the options are invalid, otherwise any error from `out` is returned
+func Gauge(out io.Writer, opts GaugeOptions) error {
+	return visual.DrawTo(visual.NewSVG(out), opts)
+}
+
+// Gauges generates a single image with several gauges joined horizontaly.
+// nothing is written if any of the options are invalid, otherwise any
+// error from `out` is returned
+func Gauges(out io.Writer, opts []GaugeOptions) error {
+	return visual.DrawTo(visual.NewSVG(out), GaugeSet(opts))
 }
@@ -1,12 +1,17 @@
 package gauge
 
 import (
+	"errors"
 	"flag"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/kylelemons/godebug/diff"
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/format"
+	"github.com/osraige/visualisations/scale"
 	"github.com/osraige/visualisations/visualtest"
 )
 
@@ -138,11 +143,116 @@ func TestGenerate(t *testing.T) {
 				LabelColour:      "white",
 				LabelSize:        20,
 			}},
+		}, {
+			golden: "dark-theme",
+			gaugeOptions: []GaugeOptions{{
+				Size:           200,
+				Padding:        10,
+				GapRadians:     1,
+				LineWidth:      10,
+				FillProportion: 0.6,
+				Label:          "60%",
+				Theme:          &visual.DarkTheme,
+			}},
+		}, {
+			golden: "scaled",
+			gaugeOptions: []GaugeOptions{{
+				Size:             200,
+				Padding:          10,
+				GapRadians:       1,
+				BackgroundColour: "white",
+				Colour:           "green",
+				LineWidth:        10,
+				Value:            1536,
+				Scale: scale.Linear{
+					Domain: [2]float64{0, 2048},
+					Range:  scale.Unit,
+					Clamp:  true,
+				},
+				Label:       "1.5GiB",
+				LabelFont:   "monospace",
+				LabelColour: "black",
+				LabelSize:   20,
+			}},
+		}, {
+			golden: "fit-label",
+			gaugeOptions: []GaugeOptions{{
+				Size:             100,
+				Padding:          10,
+				GapRadians:       1,
+				BackgroundColour: "white",
+				Colour:           "green",
+				LineWidth:        6,
+				FillProportion:   0.42,
+				Label:            "42 requests/s",
+				LabelFont:        "Helvetica, sans-serif",
+				LabelColour:      "black",
+				LabelSize:        20,
+				FitLabel:         true,
+			}},
+		}, {
+			golden: "dark-mode",
+			gaugeOptions: []GaugeOptions{{
+				Size:           100,
+				Padding:        10,
+				GapRadians:     1,
+				FillProportion: 0.5,
+				Label:          "50%",
+				Theme:          &visual.LightTheme,
+				DarkTheme:      &visual.DarkTheme,
+			}},
+		}, {
+			golden: "gradient",
+			gaugeOptions: []GaugeOptions{{
+				Size:           100,
+				Padding:        10,
+				GapRadians:     1,
+				LineWidth:      6,
+				FillProportion: 0.75,
+				Paint: &visual.Paint{Gradient: &visual.Gradient{Stops: []visual.GradientStop{
+					{Offset: 0, Colour: "green"},
+					{Offset: 1, Colour: "red"},
+				}}},
+				BackgroundPaint: &visual.Paint{Pattern: &visual.Pattern{
+					Kind: visual.PatternDots, Colour: "grey", Spacing: 3, Width: 1,
+				}},
+			}},
+		}, {
+			golden: "shadow",
+			gaugeOptions: []GaugeOptions{{
+				Size:           100,
+				Padding:        10,
+				GapRadians:     1,
+				LineWidth:      6,
+				FillProportion: 0.25,
+				Label:          "25%",
+				LabelSize:      20,
+				Theme:          &visual.LightTheme,
+				ArcFilter:      &visual.Filter{Kind: visual.FilterDropShadow, Radius: 1},
+				LabelFilter:    &visual.Filter{Kind: visual.FilterGlow, Colour: "gold"},
+			}},
+		}, {
+			golden: "inaccessible",
+			gaugeOptions: []GaugeOptions{{
+				Size:             100,
+				Padding:          10,
+				GapRadians:       1,
+				BackgroundColour: "white",
+				Colour:           "green",
+				LineWidth:        6,
+				FillProportion:   0.5,
+				Label:            "50%",
+				LabelColour:      "black",
+				LabelSize:        20,
+				Accessibility:    visual.Accessibility{Disabled: true},
+			}},
 		},
 	} {
 		t.Run(testcase.golden, func(t *testing.T) {
 			builder := &strings.Builder{}
-			Gauges(builder, testcase.gaugeOptions)
+			if err := Gauges(builder, testcase.gaugeOptions); err != nil {
+				t.Fatal(err)
+			}
 			got := builder.String()
 			want := visualtest.GoldenValue(t, testcase.golden, got, *update)
 			if got != want {
@@ -151,3 +261,249 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFromJSON(t *testing.T) {
+	r, err := visual.NewFromJSON("gauges", []byte(`[{
+		"Size": 500, "Padding": 30, "GapRadians": 1,
+		"BackgroundColour": "white", "Colour": "green",
+		"LineWidth": 30, "FillProportion": 0.1,
+		"Label": "some", "LabelFont": "monospace",
+		"LabelColour": "white", "LabelSize": 50
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := r.PreferredSize(); w != 500 || h != 500 {
+		t.Errorf("PreferredSize() = %v, %v, want 500, 500", w, h)
+	}
+	builder := &strings.Builder{}
+	if err := r.Render(builder); err != nil {
+		t.Fatal(err)
+	}
+	got := builder.String()
+	want := visualtest.GoldenValue(t, "some", got, false)
+	if got != want {
+		t.Errorf("mismatched output:\n%s", diff.Diff(want, got))
+	}
+}
+
+func TestClasses(t *testing.T) {
+	builder := &strings.Builder{}
+	svg := visual.NewSVG(builder)
+	svg.Classes = true
+	err := visual.DrawTo(svg, GaugeOptions{
+		Size:             100,
+		Padding:          10,
+		GapRadians:       1,
+		BackgroundColour: "white",
+		Colour:           "green",
+		LineWidth:        6,
+		FillProportion:   0.5,
+		Label:            "50%",
+		LabelColour:      "black",
+		LabelSize:        20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := builder.String()
+	want := visualtest.GoldenValue(t, "classes", got, *update)
+	if got != want {
+		t.Errorf("mismatched output:\n%s", diff.Diff(want, got))
+	}
+}
+
+func TestClassesWithFonts(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/font.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	font, err := visual.ParseFont("Test Font", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := &strings.Builder{}
+	svg := visual.NewSVG(builder)
+	svg.Classes = true
+	svg.Fonts = []*visual.Font{font}
+	err = visual.DrawTo(svg, GaugeOptions{
+		Size:             100,
+		Padding:          10,
+		BackgroundColour: "white",
+		Colour:           "green",
+		LineWidth:        6,
+		FillProportion:   0.5,
+		Label:            "AB",
+		LabelColour:      "black",
+		LabelFont:        "Test Font, sans-serif",
+		LabelSize:        20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := builder.String()
+	want := visualtest.GoldenValue(t, "classes-fonts", got, *update)
+	if got != want {
+		t.Errorf("mismatched output:\n%s", diff.Diff(want, got))
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for _, testcase := range []struct {
+		name        string
+		options     GaugeOptions
+		title, desc string
+	}{
+		{
+			name:    "unlabelled",
+			options: GaugeOptions{FillProportion: 0.724},
+			title:   "Gauge",
+			desc:    "Gauge at 72%",
+		},
+		{
+			name:    "labelled",
+			options: GaugeOptions{FillProportion: 0.72, Label: "CPU"},
+			title:   "CPU",
+			desc:    "Gauge at 72% labelled CPU",
+		},
+		{
+			name: "scaled",
+			options: GaugeOptions{
+				Value: 512,
+				Scale: scale.Linear{Domain: [2]float64{0, 2048}, Range: scale.Unit},
+			},
+			title: "Gauge",
+			desc:  "Gauge at 25%",
+		},
+		{
+			name: "formatted",
+			options: GaugeOptions{
+				Value:       512,
+				Scale:       scale.Linear{Domain: [2]float64{0, 2048}, Range: scale.Unit},
+				LabelFormat: format.BinaryBytes(3),
+			},
+			title: "512 B",
+			desc:  "Gauge at 25% labelled 512 B",
+		},
+		{
+			name: "overridden",
+			options: GaugeOptions{
+				Label:         "CPU",
+				Accessibility: visual.Accessibility{Description: "Idle"},
+			},
+			title: "CPU",
+			desc:  "Idle",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			title, desc := testcase.options.Describe()
+			if title != testcase.title || desc != testcase.desc {
+				t.Errorf("got %q, %q, want %q, %q", title, desc, testcase.title, testcase.desc)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := GaugeOptions{
+		Size:           100,
+		Padding:        10,
+		GapRadians:     1,
+		LineWidth:      6,
+		FillProportion: 0.5,
+		LabelSize:      20,
+	}
+	for _, testcase := range []struct {
+		name   string
+		opts   []GaugeOptions
+		fields []string
+	}{
+		{name: "valid", opts: []GaugeOptions{valid}},
+		{
+			name: "overfilled",
+			opts: []GaugeOptions{valid, func() GaugeOptions {
+				g := valid
+				g.FillProportion = 1.5
+				return g
+			}()},
+			fields: []string{"[1].FillProportion"},
+		}, {
+			name: "negative sizes",
+			opts: []GaugeOptions{func() GaugeOptions {
+				g := valid
+				g.Size = -100
+				g.LineWidth = -1
+				return g
+			}()},
+			fields: []string{"[0].Size", "[0].Padding", "[0].LineWidth"},
+		}, {
+			name: "unclamped scale",
+			opts: []GaugeOptions{func() GaugeOptions {
+				g := valid
+				g.Value = 3000
+				g.Scale = scale.Linear{
+					Domain: [2]float64{0, 2048},
+					Range:  scale.Unit,
+				}
+				return g
+			}()},
+			fields: []string{"[0].Value"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			err := Gauges(builder, testcase.opts)
+			if len(testcase.fields) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			verr, ok := err.(*visual.ValidationError)
+			if !ok {
+				t.Fatalf("got %v, want a *visual.ValidationError", err)
+			}
+			var fields []string
+			for _, fe := range verr.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if strings.Join(fields, " ") != strings.Join(testcase.fields, " ") {
+				t.Errorf("got invalid fields %v, want %v", fields, testcase.fields)
+			}
+			if builder.Len() != 0 {
+				t.Errorf("wrote %d bytes for invalid options", builder.Len())
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	dropped := errors.New("connection dropped")
+	w := &visualtest.FailingWriter{After: 100, Err: dropped}
+	err := Gauge(w, GaugeOptions{Size: 100, FillProportion: 0.5})
+	if err != dropped {
+		t.Errorf("got %v, want %v", err, dropped)
+	}
+}
+
+func TestGaugeSetDescribe(t *testing.T) {
+	set := GaugeSet{
+		{FillProportion: 0.5, Label: "CPU"},
+		{FillProportion: 0.2, Accessibility: visual.Accessibility{Disabled: true}},
+		{FillProportion: 0.1, Label: "RAM"},
+	}
+	for _, testcase := range []struct {
+		name  string
+		set   GaugeSet
+		title string
+	}{
+		{name: "disabled left out", set: set, title: "2 gauges"},
+		{name: "single", set: set[:2], title: "1 gauge"},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if title, _ := testcase.set.Describe(); title != testcase.title {
+				t.Errorf("got %q, want %q", title, testcase.title)
+			}
+		})
+	}
+}
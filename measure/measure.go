@@ -0,0 +1,224 @@
+// Package measure estimates the size of text without a renderer, using
+// embedded advance widths for the common css font families
+package measure
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	visual "github.com/osraige/visualisations"
+)
+
+// Ellipsis is appended to truncated text
+const Ellipsis = "..."
+
+// defaultFontSize is the css default used when a style has no size
+const defaultFontSize = 16
+
+// Family holds the metrics of a font family. all values are in
+// thousandths of an em
+type Family struct {
+	Name string
+	// widths are the advance widths of printable ascii, from space to ~
+	widths [95]int
+	// Average is used for runes without an entry in widths
+	Average int
+	Ascent  int
+	Descent int
+}
+
+var (
+	// SansSerif uses the metrics of Helvetica
+	SansSerif = Family{
+		Name: "sans-serif",
+		widths: [95]int{
+			278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+			556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+			1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+			667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+			333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+			556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+		},
+		Average: 556,
+		Ascent:  718,
+		Descent: 207,
+	}
+	// Serif uses the metrics of Times
+	Serif = Family{
+		Name: "serif",
+		widths: [95]int{
+			250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+			500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+			921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+			556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+			333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+			500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541,
+		},
+		Average: 500,
+		Ascent:  683,
+		Descent: 217,
+	}
+	// Monospace uses the metrics of Courier
+	Monospace = Family{
+		Name:    "monospace",
+		Average: 600,
+		Ascent:  629,
+		Descent: 157,
+	}
+)
+
+func init() {
+	for i := range Monospace.widths {
+		Monospace.widths[i] = 600
+	}
+}
+
+// familyNames maps lowercased font names onto the family with the
+// closest metrics
+var familyNames = map[string]*Family{
+	"monospace":        &Monospace,
+	"courier":          &Monospace,
+	"courier new":      &Monospace,
+	"menlo":            &Monospace,
+	"monaco":           &Monospace,
+	"consolas":         &Monospace,
+	"dejavu sans mono": &Monospace,
+	"ui-monospace":     &Monospace,
+	"serif":            &Serif,
+	"times":            &Serif,
+	"times new roman":  &Serif,
+	"georgia":          &Serif,
+	"ui-serif":         &Serif,
+	"sans-serif":       &SansSerif,
+	"helvetica":        &SansSerif,
+	"arial":            &SansSerif,
+	"system-ui":        &SansSerif,
+	"ui-sans-serif":    &SansSerif,
+}
+
+// Lookup finds the metrics for a css font stack such as
+// "Menlo, monospace", using the first font it recognises. unknown
+// stacks use SansSerif
+func Lookup(fontFamily string) Family {
+	for _, name := range strings.Split(fontFamily, ",") {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'`))
+		if f, ok := familyNames[name]; ok {
+			return *f
+		}
+	}
+	return SansSerif
+}
+
+// advance returns the width of `r` in thousandths of an em
+func (f Family) advance(r rune) int {
+	switch {
+	case r >= ' ' && r <= '~':
+		return f.widths[r-' ']
+	case unicode.Is(unicode.Mn, r) || unicode.IsControl(r):
+		return 0
+	case isWide(r):
+		return 1000
+	}
+	return f.Average
+}
+
+// isWide reports whether `r` is usually drawn a full em wide, such as
+// cjk ideographs and fullwidth forms
+func isWide(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0xff01 && r <= 0xff60) || (r >= 0x1f300 && r <= 0x1faff)
+}
+
+// Width is the width of `s` in pixels at `fontSize`
+func (f Family) Width(s string, fontSize int) float64 {
+	total := 0
+	for _, r := range s {
+		total += f.advance(r)
+	}
+	return float64(total) * float64(fontSize) / 1000
+}
+
+// Height is the height from the descender to the ascender in pixels
+// at `fontSize`
+func (f Family) Height(fontSize int) float64 {
+	return float64(f.Ascent+f.Descent) * float64(fontSize) / 1000
+}
+
+// Width is the width of `s` in pixels, in the given css font stack and
+// size
+func Width(s, fontFamily string, fontSize int) float64 {
+	return Lookup(fontFamily).Width(s, fontSize)
+}
+
+// Height is the height of a line of text in pixels, in the given css
+// font stack and size
+func Height(fontFamily string, fontSize int) float64 {
+	return Lookup(fontFamily).Height(fontSize)
+}
+
+// Size is the width and height of `s` drawn with `style`. a style with
+// no font size uses the css default of 16px
+func Size(s string, style visual.Style) (width, height float64) {
+	size := defaultFontSize
+	if style.FontSize != nil {
+		size = *style.FontSize
+	}
+	f := Lookup(style.FontFamily)
+	return f.Width(s, size), f.Height(size)
+}
+
+// Truncate shortens `s` so that it, along with an ellipsis, fits within
+// `maxWidth` pixels. text that already fits is returned unchanged
+func Truncate(s string, maxWidth float64, fontFamily string, fontSize int) string {
+	f := Lookup(fontFamily)
+	if f.Width(s, fontSize) <= maxWidth {
+		return s
+	}
+	limit := maxWidth - f.Width(Ellipsis, fontSize)
+	width := 0.0
+	for i, r := range s {
+		width += float64(f.advance(r)) * float64(fontSize) / 1000
+		if width > limit {
+			return s[:i] + Ellipsis
+		}
+	}
+	return s
+}
+
+// TruncateRunes shortens `s` to `n` runes, replacing the end with an
+// ellipsis. when `n` leaves no room for the ellipsis it is added after
+// the first `n` runes, so the result is longer than `n` runes. nothing
+// is left when `n` isn't positive
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	keep := n
+	if keep > len(Ellipsis) {
+		keep -= len(Ellipsis)
+	}
+	runes := []rune(s)
+	return string(runes[:keep]) + Ellipsis
+}
+
+// FitSize returns the largest font size no bigger than `maxSize` at
+// which `s` fits within `maxWidth` pixels, and at least 1
+func FitSize(s string, maxWidth float64, fontFamily string, maxSize int) int {
+	f := Lookup(fontFamily)
+	width := f.Width(s, 1000) / 1000
+	if width == 0 {
+		return maxSize
+	}
+	size := int(maxWidth / width)
+	if size > maxSize {
+		size = maxSize
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
@@ -0,0 +1,92 @@
+package measure
+
+import (
+	"math"
+	"testing"
+
+	visual "github.com/osraige/visualisations"
+)
+
+func TestWidth(t *testing.T) {
+	for _, testcase := range []struct {
+		text   string
+		family string
+		size   int
+		want   float64
+	}{
+		{text: "", family: "monospace", size: 12, want: 0},
+		{text: "hello", family: "monospace", size: 10, want: 30},
+		{text: "hello", family: "Menlo, monospace", size: 10, want: 30},
+		{text: "hello", family: "'Helvetica Neue', Arial", size: 10, want: 21.12},
+		{text: "hello", family: "Times New Roman", size: 10, want: 20},
+		{text: "hello", family: "unknown", size: 10, want: 21.12},
+		{text: "日本", family: "sans-serif", size: 10, want: 20},
+		{text: "é", family: "sans-serif", size: 10, want: 5.56},
+	} {
+		t.Run(testcase.text+"/"+testcase.family, func(t *testing.T) {
+			got := Width(testcase.text, testcase.family, testcase.size)
+			if math.Abs(got-testcase.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestSize(t *testing.T) {
+	w, h := Size("ab", visual.Style{FontFamily: "monospace", FontSize: visual.Int(20)})
+	if w != 24 || math.Abs(h-15.72) > 1e-9 {
+		t.Errorf("got %v x %v", w, h)
+	}
+	if w, _ := Size("ab", visual.Style{FontFamily: "monospace"}); w != 19.2 {
+		t.Errorf("default size: got %v", w)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, testcase := range []struct {
+		text     string
+		maxWidth float64
+		want     string
+	}{
+		{text: "fits", maxWidth: 100, want: "fits"},
+		{text: "abcdefghij", maxWidth: 60, want: "abcdefghij"},
+		{text: "abcdefghijk", maxWidth: 60, want: "abcdefg..."},
+		{text: "ñandú-ñandú", maxWidth: 48, want: "ñandú..."},
+		{text: "abcdef", maxWidth: 10, want: "..."},
+	} {
+		if got := Truncate(testcase.text, testcase.maxWidth, "monospace", 10); got != testcase.want {
+			t.Errorf("%s: got %q, want %q", testcase.text, got, testcase.want)
+		}
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	for _, testcase := range []struct {
+		text string
+		n    int
+		want string
+	}{
+		{text: "fits", n: 4, want: "fits"},
+		{text: "ñandú-ñandú", n: 8, want: "ñandú..."},
+		{text: "abcdef", n: 4, want: "a..."},
+		{text: "abcdef", n: 2, want: "ab..."},
+		{text: "abcdef", n: 0, want: ""},
+		{text: "abcdef", n: -1, want: ""},
+	} {
+		if got := TruncateRunes(testcase.text, testcase.n); got != testcase.want {
+			t.Errorf("%s to %d: got %q, want %q", testcase.text, testcase.n, got, testcase.want)
+		}
+	}
+}
+
+func TestFitSize(t *testing.T) {
+	if got := FitSize("abcd", 120, "monospace", 100); got != 50 {
+		t.Errorf("got %d, want 50", got)
+	}
+	if got := FitSize("abcd", 120, "monospace", 20); got != 20 {
+		t.Errorf("got %d, want 20", got)
+	}
+	if got := FitSize("abcd", 0, "monospace", 20); got != 1 {
+		t.Errorf("got %d, want 1", got)
+	}
+}
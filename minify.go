@@ -0,0 +1,377 @@
+package visualisations
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// decimal matches numbers written with a decimal point
+var decimal = regexp.MustCompile(`-?\d*\.\d+`)
+
+// defaultDeclarations are css declarations that set a property to its
+// initial value. they are only dropped when nothing else in the
+// document could set the property for the element, that is when the
+// document has no stylesheet and no ancestor sets the property
+var defaultDeclarations = map[string]bool{
+	"fill-opacity:1":         true,
+	"stroke-opacity:1":       true,
+	"opacity:1":              true,
+	"stroke:none":            true,
+	"stroke-width:1":         true,
+	"stroke-linecap:butt":    true,
+	"font-weight:normal":     true,
+	"font-weight:400":        true,
+	"text-anchor:start":      true,
+	"dominant-baseline:auto": true,
+}
+
+// zeroAttributes are the attributes that default to zero on the
+// shapes the canvas writes. gradients have other defaults
+var zeroAttributes = map[string]bool{
+	"x": true, "y": true, "cx": true, "cy": true,
+	"x1": true, "y1": true, "x2": true, "y2": true,
+}
+
+var shapes = map[string]bool{
+	"rect": true, "circle": true, "ellipse": true, "line": true,
+	"text": true, "tspan": true, "image": true, "use": true, "pattern": true,
+}
+
+// Minify rewrites the svg document read from `in` to be as small as
+// possible, for inlining into html:
+//
+//   - whitespace between elements, comments and the xml declaration
+//     are removed
+//   - numbers lose trailing zeros
+//   - attributes and css declarations set to their defaults are dropped
+//   - any style used by more than one element is moved into a class
+func Minify(out io.Writer, in io.Reader) error {
+	var raw []xml.Token
+	// a stylesheet can set any property of any element, so defaults
+	// are kept in documents that have one
+	sheeted := false
+	// raw tokens keep namespace prefixes as written but aren't checked
+	// for matching tags, so open elements are tracked here
+	var open []xml.Name
+	d := xml.NewDecoder(in)
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			if len(open) > 0 {
+				return fmt.Errorf("visualisations: minify: unclosed <%s>", name(open[len(open)-1]))
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, t.Name)
+			if t.Name.Local == "style" {
+				sheeted = true
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "class" && attr.Name.Space == "" {
+					sheeted = true
+				}
+			}
+			raw = append(raw, t.Copy())
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != t.Name {
+				return fmt.Errorf("visualisations: minify: unexpected </%s>", name(t.Name))
+			}
+			open = open[:len(open)-1]
+			raw = append(raw, t)
+		case xml.CharData:
+			raw = append(raw, t.Copy())
+		}
+	}
+
+	var tokens []xml.Token
+	styles := map[string]int{}
+	usesXlink := false
+	// set holds the properties set by each open element or its
+	// ancestors, which its descendants inherit
+	set := []map[string]bool{{}}
+	for _, tok := range raw {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, t.Name)
+			inherited := set[len(set)-1]
+			t = minifyElement(t, func(prop string) bool {
+				return sheeted || inherited[prop]
+			})
+			set = append(set, withProperties(inherited, t.Attr))
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xlink" {
+					usesXlink = true
+				}
+				if attr.Name.Local == "style" && attr.Name.Space == "" {
+					styles[attr.Value]++
+				}
+			}
+			tokens = append(tokens, t)
+		case xml.EndElement:
+			open = open[:len(open)-1]
+			set = set[:len(set)-1]
+			tokens = append(tokens, t)
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			if len(open) > 0 && open[len(open)-1].Local == "style" {
+				t = xml.CharData(minifyCSS(string(t)))
+			}
+			tokens = append(tokens, t)
+		}
+	}
+
+	// classes are named in the order their styles first appear
+	classes := map[string]string{}
+	var sheet strings.Builder
+	for _, tok := range tokens {
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range start.Attr {
+			style := attr.Value
+			if attr.Name.Local != "style" || styles[style] < 2 || classes[style] != "" {
+				continue
+			}
+			classes[style] = "s" + strconv.FormatInt(int64(len(classes)), 36)
+			sheet.WriteString("." + classes[style] + "{" + style + "}")
+		}
+	}
+
+	w := NewErrWriter(out)
+	// the classes go in a stylesheet before the first child of the svg
+	// element that isn't its title or description
+	depth := 0
+	writeSheet := func() {
+		if sheet.Len() > 0 {
+			io.WriteString(w, "<style>"+sheet.String()+"</style>")
+			sheet.Reset()
+		}
+	}
+	for i, tok := range tokens {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 1 && t.Name.Local != "title" && t.Name.Local != "desc" {
+				writeSheet()
+			}
+			depth++
+			io.WriteString(w, "<"+name(t.Name))
+			for _, attr := range hoist(t.Attr, classes) {
+				if attr.Name.Space == "xmlns" && attr.Name.Local == "xlink" && !usesXlink {
+					continue
+				}
+				io.WriteString(w, " "+name(attr.Name)+`="`)
+				xml.EscapeText(w, []byte(attr.Value))
+				io.WriteString(w, `"`)
+			}
+			// elements without content are closed straight away
+			if end, ok := next(tokens, i).(xml.EndElement); ok && end.Name == t.Name {
+				io.WriteString(w, "/>")
+			} else {
+				io.WriteString(w, ">")
+			}
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				writeSheet()
+			}
+			if start, ok := tokens[i-1].(xml.StartElement); ok && start.Name == t.Name {
+				continue
+			}
+			io.WriteString(w, "</"+name(t.Name)+">")
+		case xml.CharData:
+			// quotes only need escaping in attributes
+			io.WriteString(w, cssEscaper.Replace(string(t)))
+		}
+	}
+	return w.Err()
+}
+
+// hoist replaces a style attribute that has a class in `classes` with
+// the class, adding it to any classes the element already has
+func hoist(attrs []xml.Attr, classes map[string]string) []xml.Attr {
+	class := ""
+	for _, attr := range attrs {
+		if attr.Name.Local == "style" && attr.Name.Space == "" {
+			class = classes[attr.Value]
+		}
+	}
+	if class == "" {
+		return attrs
+	}
+	var out []xml.Attr
+	merged := false
+	for _, attr := range attrs {
+		switch {
+		case attr.Name.Space != "":
+		case attr.Name.Local == "style":
+			continue
+		case attr.Name.Local == "class":
+			attr.Value += " " + class
+			merged = true
+		}
+		out = append(out, attr)
+	}
+	if !merged {
+		out = append(out, xml.Attr{Name: xml.Name{Local: "class"}, Value: class})
+	}
+	return out
+}
+
+func next(tokens []xml.Token, i int) xml.Token {
+	if i+1 < len(tokens) {
+		return tokens[i+1]
+	}
+	return nil
+}
+
+func name(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+// withProperties adds the properties `attrs` set, either as attributes
+// or in a style, to those `inherited`
+func withProperties(inherited map[string]bool, attrs []xml.Attr) map[string]bool {
+	set := inherited
+	add := func(prop string) {
+		if set[prop] {
+			return
+		}
+		if len(set) == len(inherited) {
+			set = make(map[string]bool, len(inherited)+1)
+			for p := range inherited {
+				set[p] = true
+			}
+		}
+		set[prop] = true
+	}
+	for _, attr := range attrs {
+		if attr.Name.Space != "" {
+			continue
+		}
+		if attr.Name.Local != "style" {
+			add(attr.Name.Local)
+			continue
+		}
+		for _, decl := range strings.Split(attr.Value, ";") {
+			if i := strings.Index(decl, ":"); i >= 0 {
+				add(strings.TrimSpace(decl[:i]))
+			}
+		}
+	}
+	return set
+}
+
+// minifyElement shortens the attributes of `e`, dropping any that are
+// left with nothing to say. default declarations are kept for the
+// properties `keep` reports
+func minifyElement(e xml.StartElement, keep func(prop string) bool) xml.StartElement {
+	attrs := e.Attr[:0]
+	for _, attr := range e.Attr {
+		if attr.Name.Space == "" {
+			attr.Value = shortenNumbers(attr.Value)
+			switch attr.Name.Local {
+			case "style":
+				attr.Value = minifyStyle(attr.Value, keep)
+			case "transform":
+				attr.Value = strings.TrimSpace(strings.NewReplacer(
+					"translate(0,0)", "", "translate(0)", "", "rotate(0)", "",
+				).Replace(attr.Value))
+			}
+			if attr.Value == "" && (attr.Name.Local == "style" || attr.Name.Local == "transform") {
+				continue
+			}
+			if attr.Value == "0" && zeroAttributes[attr.Name.Local] && shapes[e.Name.Local] {
+				continue
+			}
+		}
+		attrs = append(attrs, attr)
+	}
+	e.Attr = attrs
+	return e
+}
+
+// cssRule matches a rule of a stylesheet
+var cssRule = regexp.MustCompile(`([^{}]+)\{([^{}]*)\}`)
+
+// cssBrace matches a brace and the whitespace around it
+var cssBrace = regexp.MustCompile(`\s*([{}])\s*`)
+
+// minifyCSS minifies the declarations of each rule in `css` as though
+// they were inline, dropping rules that are left empty
+func minifyCSS(css string) string {
+	css = cssBrace.ReplaceAllString(strings.TrimSpace(css), "$1")
+	return cssRule.ReplaceAllStringFunc(css, func(rule string) string {
+		m := cssRule.FindStringSubmatch(rule)
+		// a document with a stylesheet keeps its defaults
+		decls := minifyStyle(shortenNumbers(m[2]), func(string) bool { return true })
+		if decls == "" {
+			return ""
+		}
+		return strings.TrimSpace(m[1]) + "{" + decls + "}"
+	})
+}
+
+// minifyStyle drops the default declarations of properties `keep`
+// doesn't report from an inline style, and shortens its colours
+func minifyStyle(style string, keep func(prop string) bool) string {
+	var decls []string
+	for _, decl := range strings.Split(style, ";") {
+		if i := strings.Index(decl, ":"); i >= 0 {
+			decl = strings.TrimSpace(decl[:i]) + ":" + strings.TrimSpace(decl[i+1:])
+		}
+		decl = strings.TrimSpace(decl)
+		if decl == "" {
+			continue
+		}
+		if defaultDeclarations[decl] && !keep(decl[:strings.Index(decl, ":")]) {
+			continue
+		}
+		if i := strings.Index(decl, ":#"); i >= 0 {
+			decl = decl[:i+1] + shortenHex(decl[i+1:])
+		}
+		decls = append(decls, decl)
+	}
+	return strings.Join(decls, ";")
+}
+
+// shortenNumbers removes the trailing zeros and leading zero of every
+// decimal number in `s`
+func shortenNumbers(s string) string {
+	return decimal.ReplaceAllStringFunc(s, func(n string) string {
+		n = strings.TrimRight(n, "0")
+		n = strings.TrimSuffix(n, ".")
+		switch {
+		case n == "" || n == "-" || n == "-0":
+			return "0"
+		case strings.HasPrefix(n, "0."):
+			return n[1:]
+		case strings.HasPrefix(n, "-0."):
+			return "-" + n[2:]
+		}
+		return n
+	})
+}
+
+// shortenHex writes a colour such as "#aabbcc" as "#abc"
+func shortenHex(c string) string {
+	if len(c) == 7 && c[1] == c[2] && c[3] == c[4] && c[5] == c[6] {
+		return "#" + c[1:2] + c[3:4] + c[5:6]
+	}
+	return c
+}
@@ -0,0 +1,100 @@
+package visualisations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "whitespace",
+			in: `<?xml version="1.0"?>
+<!-- comment -->
+<svg width="10.00" height="10.00"
+     xmlns="http://www.w3.org/2000/svg"
+     xmlns:xlink="http://www.w3.org/1999/xlink">
+<g id="a">
+</g>
+<text x="1.50" y="0.00"> a &amp; b </text>
+</svg>
+`,
+			want: `<svg width="10" height="10" xmlns="http://www.w3.org/2000/svg"><g id="a"/><text x="1.5"> a &amp; b </text></svg>`,
+		},
+		{
+			name: "numbers",
+			in:   `<svg><path d="M0.50,-0.00 L-0.25,10.10 A5.00,5.00 0 0 1 100.00,2.00" /></svg>`,
+			want: `<svg><path d="M.5,0 L-.25,10.1 A5,5 0 0 1 100,2"/></svg>`,
+		},
+		{
+			name: "defaults",
+			in: `<svg><g transform="translate(0.00,0.00)">` +
+				`<rect x="0.00" y="0.00" width="2.00" height="0.00" style="fill:#aabbcc;fill-opacity:1.000000;stroke:none;stroke-width:1.0" />` +
+				`<line x1="0" y1="1" x2="0" y2="0" style="stroke-linecap:butt" />` +
+				`</g></svg>`,
+			want: `<svg><g><rect width="2" height="0" style="fill:#abc"/><line y1="1"/></g></svg>`,
+		},
+		{
+			name: "classes",
+			in: `<svg><rect style="fill:red;stroke:blue" /><rect style="fill:red" />` +
+				`<circle style="fill:red;stroke:blue" /><circle style="fill:red;stroke:blue" /></svg>`,
+			want: `<svg><style>.s0{fill:red;stroke:blue}</style><rect class="s0"/>` +
+				`<rect style="fill:red"/><circle class="s0"/><circle class="s0"/></svg>`,
+		},
+		{
+			name: "existing classes",
+			in: `<svg><title>a</title><rect class="a" style="fill:red" /><rect style="fill:red" />` +
+				`<style>.a { stroke: blue; stroke-width: 1.00 }` + "\n" + `.b { stroke-width: 1 }</style></svg>`,
+			want: `<svg><title>a</title><style>.s0{fill:red}</style><rect class="a s0"/><rect class="s0"/>` +
+				`<style>.a{stroke:blue;stroke-width:1}.b{stroke-width:1}</style></svg>`,
+		},
+		{
+			name: "inherited defaults",
+			in: `<svg><g style="stroke:red;stroke-width:3"><rect style="stroke:none;fill-opacity:1" /></g>` +
+				`<rect style="stroke:none;stroke-width:1" /></svg>`,
+			want: `<svg><g style="stroke:red;stroke-width:3"><rect style="stroke:none"/></g><rect/></svg>`,
+		},
+		{
+			name: "media query",
+			in:   "<svg><style>\n@media (prefers-color-scheme: dark) {\n.a{fill:#ffffff !important}\n}\n</style></svg>",
+			want: `<svg><style>@media (prefers-color-scheme: dark){.a{fill:#ffffff !important}}</style></svg>`,
+		},
+		{
+			name: "gradient defaults",
+			in:   `<svg><linearGradient x1="1.00" y1="0.5" x2="0.00" y2="0.5"/><rect x="0.00" y="1"/></svg>`,
+			want: `<svg><linearGradient x1="1" y1=".5" x2="0" y2=".5"/><rect y="1"/></svg>`,
+		},
+		{
+			name: "quoted text",
+			in:   `<svg><text>"a" &amp; 'b' &lt; c</text></svg>`,
+			want: `<svg><text>"a" &amp; 'b' &lt; c</text></svg>`,
+		},
+		{
+			name: "xlink",
+			in: `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><g id="a" />` +
+				`<animate xlink:href="#a" from="0" to="1" dur="0.75s" /></svg>`,
+			want: `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><g id="a"/>` +
+				`<animate xlink:href="#a" from="0" to="1" dur=".75s"/></svg>`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := Minify(&out, strings.NewReader(testcase.in)); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != testcase.want {
+				t.Errorf("got  %s\nwant %s", got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestMinifyInvalid(t *testing.T) {
+	if err := Minify(&strings.Builder{}, strings.NewReader("<svg><g></svg>")); err == nil {
+		t.Error("got no error for mismatched tags")
+	}
+}
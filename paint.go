@@ -0,0 +1,212 @@
+package visualisations
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// Paint fills or strokes a shape with a gradient or pattern instead of
+// a flat colour. exactly one of its fields should be set. svg canvases
+// define each paint once in <defs> and refer to it by a generated id,
+// other canvases use the style's flat colour instead
+type Paint struct {
+	Gradient *Gradient
+	Pattern  *Pattern
+}
+
+// Gradient blends between colours across a shape
+type Gradient struct {
+	// Radial blends outwards from the centre of the shape instead of
+	// along a line across it
+	Radial bool
+	// Angle is the direction of a linear gradient in degrees clockwise
+	// from left to right
+	Angle float64
+	Stops []GradientStop
+}
+
+// GradientStop is a colour at a point along a gradient
+type GradientStop struct {
+	// Offset is how far along the gradient the colour is, from 0 to 1
+	Offset float64
+	Colour string
+	// Opacity defaults to opaque
+	Opacity *float64
+}
+
+type PatternKind string
+
+const (
+	PatternStripes    = PatternKind("stripes")
+	PatternDots       = PatternKind("dots")
+	PatternCrosshatch = PatternKind("crosshatch")
+)
+
+// Pattern repeats stripes or dots across a shape
+type Pattern struct {
+	Kind PatternKind
+	// Colour is the colour of the stripes or dots
+	Colour string
+	// Background fills the space between them, when set
+	Background string
+	// Spacing is the distance between stripes or dots in pixels,
+	// defaults to 6
+	Spacing float64
+	// Width is the width of the stripes or diameter of the dots in
+	// pixels, defaults to 2
+	Width float64
+	// Angle rotates the pattern clockwise in degrees, at 0 stripes are
+	// vertical
+	Angle float64
+}
+
+// Validate reports every option that would stop the paint from being
+// drawn correctly
+func (p Paint) Validate() error {
+	v := &ValidationError{}
+	v.Check((p.Gradient == nil) != (p.Pattern == nil), "Gradient", p.Gradient,
+		"exactly one of Gradient and Pattern must be set")
+	if p.Gradient != nil {
+		v.Nest("Gradient", p.Gradient.Validate())
+	}
+	if p.Pattern != nil {
+		v.Nest("Pattern", p.Pattern.Validate())
+	}
+	return v.Err()
+}
+
+func (g Gradient) Validate() error {
+	v := &ValidationError{}
+	v.Check(len(g.Stops) > 0, "Stops", g.Stops, "must have at least one stop")
+	for i, stop := range g.Stops {
+		field := fmt.Sprintf("Stops[%d]", i)
+		v.Between(field+".Offset", stop.Offset, 0, 1)
+		if i > 0 {
+			v.Check(stop.Offset >= g.Stops[i-1].Offset, field+".Offset", stop.Offset,
+				"must not be before the previous stop")
+		}
+		v.Check(stop.Colour != "", field+".Colour", stop.Colour, "must be set")
+		if stop.Opacity != nil {
+			v.Between(field+".Opacity", *stop.Opacity, 0, 1)
+		}
+	}
+	return v.Err()
+}
+
+func (p Pattern) Validate() error {
+	v := &ValidationError{}
+	switch p.Kind {
+	case PatternStripes, PatternDots, PatternCrosshatch:
+	default:
+		v.Add("Kind", p.Kind, "must be stripes, dots or crosshatch")
+	}
+	v.Check(p.Colour != "", "Colour", p.Colour, "must be set")
+	p = p.withDefaults()
+	v.Positive("Spacing", p.Spacing)
+	v.Positive("Width", p.Width)
+	v.Check(p.Width <= p.Spacing, "Width", p.Width, "must be at most Spacing")
+	return v.Err()
+}
+
+func (p Pattern) withDefaults() Pattern {
+	if p.Spacing == 0 {
+		p.Spacing = 6
+	}
+	if p.Width == 0 {
+		p.Width = 2
+	}
+	return p
+}
+
+// Colour is the flat colour closest to the paint, for canvases that
+// can't draw it. it is empty for a nil paint
+func (p *Paint) Colour() string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Gradient != nil && len(p.Gradient.Stops) > 0:
+		return p.Gradient.Stops[0].Colour
+	case p.Pattern != nil:
+		return p.Pattern.Colour
+	}
+	return ""
+}
+
+// definition returns the svg element defining the paint, and the id
+// it is given there
+func (p Paint) definition() (def, id string) {
+	var b strings.Builder
+	switch {
+	case p.Gradient != nil:
+		p.Gradient.write(&b)
+	case p.Pattern != nil:
+		p.Pattern.write(&b)
+	}
+	return withID(b.String())
+}
+
+// withID gives the element `def` an id generated from its definition,
+// so that anything used many times is only defined once
+func withID(def string) (string, string) {
+	h := fnv.New32a()
+	h.Write([]byte(def))
+	id := fmt.Sprintf("vis-%08x", h.Sum32())
+	// the id goes after the tag name
+	i := strings.IndexAny(def, " >")
+	return def[:i] + ` id="` + id + `"` + def[i:], id
+}
+
+func (g Gradient) write(b *strings.Builder) {
+	if g.Radial {
+		b.WriteString("<radialGradient>\n")
+	} else {
+		dx, dy := math.Cos(g.Angle*math.Pi/180)/2, math.Sin(g.Angle*math.Pi/180)/2
+		fmt.Fprintf(b, `<linearGradient x1="%s" y1="%s" x2="%s" y2="%s">`+"\n",
+			number(0.5-dx), number(0.5-dy), number(0.5+dx), number(0.5+dy))
+	}
+	for _, stop := range g.Stops {
+		fmt.Fprintf(b, `<stop offset="%s" stop-color="%s"`, number(stop.Offset), escape(stop.Colour))
+		if stop.Opacity != nil {
+			fmt.Fprintf(b, ` stop-opacity="%s"`, number(*stop.Opacity))
+		}
+		b.WriteString(" />\n")
+	}
+	if g.Radial {
+		b.WriteString("</radialGradient>\n")
+	} else {
+		b.WriteString("</linearGradient>\n")
+	}
+}
+
+func (p Pattern) write(b *strings.Builder) {
+	p = p.withDefaults()
+	s, w := number(p.Spacing), number(p.Width)
+	fmt.Fprintf(b, `<pattern width="%s" height="%s" patternUnits="userSpaceOnUse"`, s, s)
+	if p.Angle != 0 {
+		fmt.Fprintf(b, ` patternTransform="rotate(%s)"`, number(p.Angle))
+	}
+	b.WriteString(">\n")
+	if p.Background != "" {
+		fmt.Fprintf(b, `<rect width="%s" height="%s" fill="%s" />`+"\n", s, s, escape(p.Background))
+	}
+	colour := escape(p.Colour)
+	switch p.Kind {
+	case PatternStripes:
+		fmt.Fprintf(b, `<rect width="%s" height="%s" fill="%s" />`+"\n", w, s, colour)
+	case PatternCrosshatch:
+		fmt.Fprintf(b, `<rect width="%s" height="%s" fill="%s" />`+"\n", w, s, colour)
+		fmt.Fprintf(b, `<rect width="%s" height="%s" fill="%s" />`+"\n", s, w, colour)
+	case PatternDots:
+		fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="%s" fill="%s" />`+"\n",
+			number(p.Spacing/2), number(p.Spacing/2), number(p.Width/2), colour)
+	}
+	b.WriteString("</pattern>\n")
+}
+
+// number writes `f` to four decimal places at most, without trailing
+// zeros
+func number(f float64) string {
+	return FormatFloat(f, 4)
+}
@@ -0,0 +1,147 @@
+package visualisations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaintDefinition(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		paint Paint
+		want  string
+	}{
+		{
+			name: "linear",
+			paint: Paint{Gradient: &Gradient{Stops: []GradientStop{
+				{Offset: 0, Colour: "green"},
+				{Offset: 1, Colour: "red", Opacity: Float(0.5)},
+			}}},
+			want: `<linearGradient id="ID" x1="0" y1="0.5" x2="1" y2="0.5">
+<stop offset="0" stop-color="green" />
+<stop offset="1" stop-color="red" stop-opacity="0.5" />
+</linearGradient>
+`,
+		},
+		{
+			name: "angled",
+			paint: Paint{Gradient: &Gradient{Angle: 90, Stops: []GradientStop{
+				{Offset: 0.25, Colour: "#fff"},
+			}}},
+			want: `<linearGradient id="ID" x1="0.5" y1="0" x2="0.5" y2="1">
+<stop offset="0.25" stop-color="#fff" />
+</linearGradient>
+`,
+		},
+		{
+			name: "radial",
+			paint: Paint{Gradient: &Gradient{Radial: true, Stops: []GradientStop{
+				{Offset: 0, Colour: "white"},
+				{Offset: 1, Colour: "black"},
+			}}},
+			want: `<radialGradient id="ID">
+<stop offset="0" stop-color="white" />
+<stop offset="1" stop-color="black" />
+</radialGradient>
+`,
+		},
+		{
+			name:  "stripes",
+			paint: Paint{Pattern: &Pattern{Kind: PatternStripes, Colour: "red", Angle: 45}},
+			want: `<pattern id="ID" width="6" height="6" patternUnits="userSpaceOnUse" patternTransform="rotate(45)">
+<rect width="2" height="6" fill="red" />
+</pattern>
+`,
+		},
+		{
+			name: "crosshatch",
+			paint: Paint{Pattern: &Pattern{
+				Kind: PatternCrosshatch, Colour: "red", Background: "white", Spacing: 10, Width: 1,
+			}},
+			want: `<pattern id="ID" width="10" height="10" patternUnits="userSpaceOnUse">
+<rect width="10" height="10" fill="white" />
+<rect width="1" height="10" fill="red" />
+<rect width="10" height="1" fill="red" />
+</pattern>
+`,
+		},
+		{
+			name:  "dots",
+			paint: Paint{Pattern: &Pattern{Kind: PatternDots, Colour: "red", Spacing: 5}},
+			want: `<pattern id="ID" width="5" height="5" patternUnits="userSpaceOnUse">
+<circle cx="2.5" cy="2.5" r="1" fill="red" />
+</pattern>
+`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			def, id := testcase.paint.definition()
+			if got := strings.Replace(def, id, "ID", 1); got != testcase.want {
+				t.Errorf("got\n%s\nwant\n%s", got, testcase.want)
+			}
+			if _, again := testcase.paint.definition(); again != id {
+				t.Errorf("id changed from %s to %s", id, again)
+			}
+		})
+	}
+}
+
+func TestPaintValidate(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		paint Paint
+		want  string
+	}{
+		{
+			name:  "valid",
+			paint: Paint{Pattern: &Pattern{Kind: PatternDots, Colour: "red"}},
+		},
+		{
+			name: "both",
+			paint: Paint{
+				Gradient: &Gradient{Stops: []GradientStop{{Colour: "red"}}},
+				Pattern:  &Pattern{Kind: PatternDots, Colour: "red"},
+			},
+			want: "Gradient",
+		},
+		{
+			name: "unordered stops",
+			paint: Paint{Gradient: &Gradient{Stops: []GradientStop{
+				{Offset: 0.5, Colour: "red"}, {Offset: 0.2, Colour: "blue"},
+			}}},
+			want: "Gradient.Stops[1].Offset",
+		},
+		{
+			name:  "no stops",
+			paint: Paint{Gradient: &Gradient{}},
+			want:  "Gradient.Stops",
+		},
+		{
+			name:  "unknown kind",
+			paint: Paint{Pattern: &Pattern{Kind: "waves", Colour: "red"}},
+			want:  "Pattern.Kind",
+		},
+		{
+			name:  "wide stripes",
+			paint: Paint{Pattern: &Pattern{Kind: PatternStripes, Colour: "red", Width: 8}},
+			want:  "Pattern.Width",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := testcase.paint.Validate()
+			if testcase.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			ve, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("got %v, want a validation error", err)
+			}
+			if field := ve.Errors[0].Field; field != testcase.want {
+				t.Errorf("invalid field %s, want %s", field, testcase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package visualisations
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// Palette is an ordered set of distinct colours for categorical data
+type Palette []Colour
+
+func paletteOf(hexes ...uint32) Palette {
+	p := make(Palette, len(hexes))
+	for i, h := range hexes {
+		p[i] = hexColour(h)
+	}
+	return p
+}
+
+var (
+	Tableau10 = paletteOf(
+		0x4e79a7, 0xf28e2b, 0xe15759, 0x76b7b2, 0x59a14f,
+		0xedc948, 0xb07aa1, 0xff9da7, 0x9c755f, 0xbab0ac,
+	)
+	// OkabeIto is a palette that stays distinguishable for the common
+	// forms of colour vision deficiency
+	OkabeIto = paletteOf(
+		0xe69f00, 0x56b4e9, 0x009e73, 0xf0e442,
+		0x0072b2, 0xd55e00, 0xcc79a7, 0x000000,
+	)
+	Category10 = paletteOf(
+		0x1f77b4, 0xff7f0e, 0x2ca02c, 0xd62728, 0x9467bd,
+		0x8c564b, 0xe377c2, 0x7f7f7f, 0xbcbd22, 0x17becf,
+	)
+	Set2 = paletteOf(
+		0x66c2a5, 0xfc8d62, 0x8da0cb, 0xe78ac3,
+		0xa6d854, 0xffd92f, 0xe5c494, 0xb3b3b3,
+	)
+	Dark2 = paletteOf(
+		0x1b9e77, 0xd95f02, 0x7570b3, 0xe7298a,
+		0x66a61e, 0xe6ab02, 0xa6761d, 0x666666,
+	)
+)
+
+// At returns the `i`th colour of the palette. once the palette is
+// exhausted it starts again from the beginning, darkening the colours
+// on every pass so they stay distinct
+func (p Palette) At(i int) Colour {
+	if len(p) == 0 {
+		return Black
+	}
+	if i < 0 {
+		i = -i
+	}
+	pass := i / len(p)
+	return p[i%len(p)].Shade(math.Min(0.15*float64(pass), 0.75))
+}
+
+// Assigner returns a function in the shape of TimelineOptions.GetColour
+// that hands out the palette's colours in the order names are first
+// seen, so distinct names get distinct colours. it is safe to share
+// between drawings made concurrently
+func (p Palette) Assigner() func(string) string {
+	var mu sync.Mutex
+	assigned := map[string]string{}
+	return func(name string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if c, ok := assigned[name]; ok {
+			return c
+		}
+		c := p.At(len(assigned)).String()
+		assigned[name] = c
+		return c
+	}
+}
+
+// Hashed returns a function in the shape of TimelineOptions.GetColour
+// that picks a colour from the palette by hashing the name. unlike
+// Assigner a name always gets the same colour, but two names may share
+// one
+func (p Palette) Hashed() func(string) string {
+	return func(name string) string {
+		if len(p) == 0 {
+			return p.At(0).String()
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(name))
+		return p.At(int(h.Sum32() % uint32(len(p)))).String()
+	}
+}
+
+// ColourScale is a continuous colour scale that interpolates between
+// evenly spaced colour stops
+type ColourScale struct {
+	stops []Colour
+}
+
+// NewColourScale creates a scale running through `stops` in order
+func NewColourScale(stops ...Colour) ColourScale {
+	return ColourScale{stops: stops}
+}
+
+func scaleOf(hexes ...uint32) ColourScale {
+	return NewColourScale(paletteOf(hexes...)...)
+}
+
+var (
+	// Viridis, Magma and Inferno are perceptually uniform sequential
+	// scales
+	Viridis = scaleOf(
+		0x440154, 0x482878, 0x3e4989, 0x31688e, 0x26828e,
+		0x1f9e89, 0x35b779, 0x6ece58, 0xb5de2b, 0xfde725,
+	)
+	Magma = scaleOf(
+		0x000004, 0x180f3d, 0x440f76, 0x721f81, 0x9e2f7f,
+		0xcd4071, 0xf1605d, 0xfd9668, 0xfeca8d, 0xfcfdbf,
+	)
+	Inferno = scaleOf(
+		0x000004, 0x1b0c41, 0x4a0c6b, 0x781c6d, 0xa52c60,
+		0xcf4446, 0xed6925, 0xfb9b06, 0xf7d13d, 0xfcffa4,
+	)
+	Blues = scaleOf(
+		0xf7fbff, 0xdeebf7, 0xc6dbef, 0x9ecae1, 0x6baed6,
+		0x4292c6, 0x2171b5, 0x08519c, 0x08306b,
+	)
+	// RdBu is a diverging scale from red through white to blue, the
+	// midpoint sits at 0.5
+	RdBu = scaleOf(
+		0x67001f, 0xb2182b, 0xd6604d, 0xf4a582, 0xfddbc7, 0xf7f7f7,
+		0xd1e5f0, 0x92c5de, 0x4393c3, 0x2166ac, 0x053061,
+	)
+)
+
+// At returns the colour at `t`, which is clamped between 0 and 1
+func (s ColourScale) At(t float64) Colour {
+	switch len(s.stops) {
+	case 0:
+		return Black
+	case 1:
+		return s.stops[0]
+	}
+	pos := clamp01(t) * float64(len(s.stops)-1)
+	i := int(pos)
+	if i >= len(s.stops)-1 {
+		return s.stops[len(s.stops)-1]
+	}
+	return s.stops[i].Mix(s.stops[i+1], pos-float64(i))
+}
+
+// Reverse returns the scale running in the opposite direction
+func (s ColourScale) Reverse() ColourScale {
+	stops := make([]Colour, len(s.stops))
+	for i, c := range s.stops {
+		stops[len(stops)-1-i] = c
+	}
+	return ColourScale{stops: stops}
+}
+
+// Palette samples `n` evenly spaced colours from the scale, including
+// both ends. it is empty when n isn't positive
+func (s ColourScale) Palette(n int) Palette {
+	if n < 1 {
+		return Palette{}
+	}
+	p := make(Palette, n)
+	for i := range p {
+		if n == 1 {
+			p[i] = s.At(0.5)
+			continue
+		}
+		p[i] = s.At(float64(i) / float64(n-1))
+	}
+	return p
+}
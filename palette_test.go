@@ -0,0 +1,78 @@
+package visualisations
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPaletteAssigner(t *testing.T) {
+	assign := Palette{RGB(255, 0, 0), RGB(0, 0, 255)}.Assigner()
+	for _, step := range []struct {
+		name string
+		want string
+	}{
+		{name: "a", want: "#ff0000"},
+		{name: "b", want: "#0000ff"},
+		{name: "a", want: "#ff0000"},
+		{name: "c", want: "#d90000"},
+	} {
+		if got := assign(step.name); got != step.want {
+			t.Errorf("%s: got %s, want %s", step.name, got, step.want)
+		}
+	}
+}
+
+func TestPaletteAssignerConcurrent(t *testing.T) {
+	assign := Tableau10.Assigner()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, name := range []string{"a", "b", "c", "d"} {
+				assign(name)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := assign("a") + assign("e"); got != Tableau10.At(0).String()+Tableau10.At(4).String() {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestColourScale(t *testing.T) {
+	for _, testcase := range []struct {
+		t    float64
+		want string
+	}{
+		{t: -1, want: "#67001f"},
+		{t: 0, want: "#67001f"},
+		{t: 0.5, want: "#f7f7f7"},
+		{t: 0.55, want: "#e4eef3"},
+		{t: 1, want: "#053061"},
+		{t: 2, want: "#053061"},
+	} {
+		if got := RdBu.At(testcase.t).String(); got != testcase.want {
+			t.Errorf("At(%v): got %s, want %s", testcase.t, got, testcase.want)
+		}
+	}
+	p := Viridis.Palette(3)
+	if got := p[0].String() + p[2].String(); got != "#440154#fde725" {
+		t.Errorf("Palette: got %s", got)
+	}
+	if got := Viridis.Reverse().At(0).String(); got != "#fde725" {
+		t.Errorf("Reverse: got %s", got)
+	}
+	if got := Viridis.Palette(-1); len(got) != 0 {
+		t.Errorf("Palette(-1): got %v", got)
+	}
+}
+
+func TestEmptyPalette(t *testing.T) {
+	if got := (Palette{}).Hashed()("x"); got != Black.String() {
+		t.Errorf("Hashed: got %s, want %s", got, Black)
+	}
+	if got := (Palette{}).Assigner()("x"); got != Black.String() {
+		t.Errorf("Assigner: got %s, want %s", got, Black)
+	}
+}
@@ -0,0 +1,287 @@
+package visualisations
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultPrecision is the number of decimals paths are written with
+const DefaultPrecision = 2
+
+type PathOp byte
+
+const (
+	OpMove  = PathOp('M')
+	OpLine  = PathOp('L')
+	OpCubic = PathOp('C')
+	OpQuad  = PathOp('Q')
+	OpArc   = PathOp('A')
+	OpClose = PathOp('Z')
+)
+
+type Point struct {
+	X, Y float64
+}
+
+// PathSegment is a single command of a path. Points holds any control
+// points followed by the end point, all in absolute coordinates
+type PathSegment struct {
+	Op     PathOp
+	Points []Point
+	// the remaining fields are only used by arcs
+	RX, RY   float64
+	Rotation float64
+	Large    bool
+	Sweep    bool
+}
+
+// Path builds the geometry of an svg path element using float
+// coordinates. the zero value writes integer coordinates, use NewPath
+// for the default precision
+type Path struct {
+	// Precision is the number of decimals coordinates are written with
+	Precision int
+	segments  []PathSegment
+	start     Point
+	current   Point
+}
+
+// NewPath creates an empty path written with DefaultPrecision
+func NewPath() *Path {
+	return &Path{Precision: DefaultPrecision}
+}
+
+func (p *Path) add(seg PathSegment) *Path {
+	p.segments = append(p.segments, seg)
+	if len(seg.Points) > 0 {
+		p.current = seg.Points[len(seg.Points)-1]
+	}
+	return p
+}
+
+// Segments returns the commands that make up the path
+func (p *Path) Segments() []PathSegment {
+	return p.segments
+}
+
+// Empty reports whether nothing has been added to the path
+func (p *Path) Empty() bool {
+	return len(p.segments) == 0
+}
+
+// Current returns the point the next command will start from
+func (p *Path) Current() Point {
+	return p.current
+}
+
+func (p *Path) MoveTo(x, y float64) *Path {
+	p.start = Point{x, y}
+	return p.add(PathSegment{Op: OpMove, Points: []Point{{x, y}}})
+}
+
+func (p *Path) LineTo(x, y float64) *Path {
+	return p.add(PathSegment{Op: OpLine, Points: []Point{{x, y}}})
+}
+
+// CubicTo draws a cubic bezier to `x`, `y` with the control points
+// `c1x`, `c1y` and `c2x`, `c2y`
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float64) *Path {
+	return p.add(PathSegment{
+		Op:     OpCubic,
+		Points: []Point{{c1x, c1y}, {c2x, c2y}, {x, y}},
+	})
+}
+
+// QuadTo draws a quadratic bezier to `x`, `y` with the control point
+// `cx`, `cy`
+func (p *Path) QuadTo(cx, cy, x, y float64) *Path {
+	return p.add(PathSegment{
+		Op:     OpQuad,
+		Points: []Point{{cx, cy}, {x, y}},
+	})
+}
+
+// ArcTo draws an elliptical arc to `x`, `y` using the same parameters
+// as the svg arc command
+func (p *Path) ArcTo(rx, ry, rotation float64, large, sweep bool, x, y float64) *Path {
+	return p.add(PathSegment{
+		Op:       OpArc,
+		Points:   []Point{{x, y}},
+		RX:       rx,
+		RY:       ry,
+		Rotation: rotation,
+		Large:    large,
+		Sweep:    sweep,
+	})
+}
+
+// Arc draws part of the circle centred on `cx`, `cy` with radius `r`,
+// from angle `start` to `end` in degrees. angles follow PointOnCircum,
+// so an end greater than start goes clockwise. the path moves to the
+// start of the arc if it is empty, otherwise a line is drawn to it
+func (p *Path) Arc(cx, cy, r, start, end float64) *Path {
+	sx, sy := PointOnCircum(cx, cy, r, start)
+	if p.Empty() {
+		p.MoveTo(sx, sy)
+	} else if cur := p.Current(); cur.X != sx || cur.Y != sy {
+		p.LineTo(sx, sy)
+	}
+	sweep := end >= start
+	// a single arc command can't draw a full circle, so split any
+	// sweep of a full turn or more into equal pieces under a turn each
+	pieces := math.Floor(math.Abs(end-start)/360) + 1
+	step := (end - start) / pieces
+	for i := 1.0; i <= pieces; i++ {
+		angle := start + step*i
+		if i == pieces {
+			angle = end
+		}
+		x, y := PointOnCircum(cx, cy, r, angle)
+		p.ArcTo(r, r, 0, math.Abs(step) > 180, sweep, x, y)
+	}
+	return p
+}
+
+// Close draws a line back to the start of the current subpath
+func (p *Path) Close() *Path {
+	p.add(PathSegment{Op: OpClose})
+	p.current = p.start
+	return p
+}
+
+// ToCubics returns an equivalent path with every quadratic bezier and
+// arc converted to cubic beziers, which most output formats can draw
+func (p *Path) ToCubics() *Path {
+	out := &Path{Precision: p.Precision}
+	for _, seg := range p.segments {
+		from := out.current
+		switch seg.Op {
+		case OpQuad:
+			q, to := seg.Points[0], seg.Points[1]
+			out.CubicTo(
+				from.X+2.0/3*(q.X-from.X), from.Y+2.0/3*(q.Y-from.Y),
+				to.X+2.0/3*(q.X-to.X), to.Y+2.0/3*(q.Y-to.Y),
+				to.X, to.Y,
+			)
+		case OpArc:
+			arcToCubics(out, from, seg)
+		case OpClose:
+			out.Close()
+		default:
+			out.add(seg)
+			if seg.Op == OpMove {
+				out.start = out.current
+			}
+		}
+	}
+	return out
+}
+
+// arcToCubics adds the arc `seg` starting at `from` to `out` as cubic
+// beziers of at most a quarter turn each, using the conversion from
+// endpoint to centre parameters in the svg spec
+func arcToCubics(out *Path, from Point, seg PathSegment) {
+	to := seg.Points[0]
+	rx, ry := math.Abs(seg.RX), math.Abs(seg.RY)
+	if rx == 0 || ry == 0 || from == to {
+		out.LineTo(to.X, to.Y)
+		return
+	}
+	sinPhi, cosPhi := math.Sincos(seg.Rotation * math.Pi / 180)
+	dx, dy := (from.X-to.X)/2, (from.Y-to.Y)/2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+	// scale up radii that are too small to reach the end point
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if seg.Large == seg.Sweep {
+		coef = -coef
+	}
+	cx1, cy1 := coef*rx*y1/ry, -coef*ry*x1/rx
+	cx := cosPhi*cx1 - sinPhi*cy1 + (from.X+to.X)/2
+	cy := sinPhi*cx1 + cosPhi*cy1 + (from.Y+to.Y)/2
+	start := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	sweep := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - start
+	if seg.Sweep && sweep < 0 {
+		sweep += 2 * math.Pi
+	} else if !seg.Sweep && sweep > 0 {
+		sweep -= 2 * math.Pi
+	}
+	// point and derivative of the ellipse at angle `t`
+	ellipse := func(t float64) (Point, Point) {
+		sin, cos := math.Sincos(t)
+		return Point{
+			cx + cosPhi*rx*cos - sinPhi*ry*sin,
+			cy + sinPhi*rx*cos + cosPhi*ry*sin,
+		}, Point{
+			-cosPhi*rx*sin - sinPhi*ry*cos,
+			-sinPhi*rx*sin + cosPhi*ry*cos,
+		}
+	}
+	n := int(math.Ceil(math.Abs(sweep)/(math.Pi/2) - 1e-9))
+	step := sweep / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	p0, d0 := ellipse(start)
+	for i := 1; i <= n; i++ {
+		p3, d3 := ellipse(start + float64(i)*step)
+		if i == n {
+			p3 = to
+		}
+		out.CubicTo(
+			p0.X+k*d0.X, p0.Y+k*d0.Y,
+			p3.X-k*d3.X, p3.Y-k*d3.Y,
+			p3.X, p3.Y,
+		)
+		p0, d0 = p3, d3
+	}
+}
+
+func (p *Path) number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', p.Precision, 64)
+	// avoid writing negative zero
+	if strings.Trim(s, "-0.") == "" {
+		return strings.TrimPrefix(s, "-")
+	}
+	return s
+}
+
+func (p *Path) point(pt Point) string {
+	return p.number(pt.X) + "," + p.number(pt.Y)
+}
+
+func flag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// String renders the path as the value of a "d" attribute
+func (p *Path) String() string {
+	parts := make([]string, 0, len(p.segments))
+	for _, seg := range p.segments {
+		cmd := string(seg.Op)
+		switch seg.Op {
+		case OpArc:
+			cmd += p.number(seg.RX) + "," + p.number(seg.RY) + " " +
+				strconv.FormatFloat(seg.Rotation, 'f', -1, 64) + " " +
+				flag(seg.Large) + " " + flag(seg.Sweep) + " " +
+				p.point(seg.Points[0])
+		case OpClose:
+		default:
+			points := make([]string, len(seg.Points))
+			for i, pt := range seg.Points {
+				points[i] = p.point(pt)
+			}
+			cmd += strings.Join(points, " ")
+		}
+		parts = append(parts, cmd)
+	}
+	return strings.Join(parts, " ")
+}
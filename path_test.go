@@ -0,0 +1,95 @@
+package visualisations
+
+import "testing"
+
+func TestPath(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		path *Path
+		want string
+	}{
+		{
+			name: "empty",
+			path: NewPath(),
+			want: "",
+		}, {
+			name: "lines",
+			path: NewPath().MoveTo(0, 0).LineTo(10.126, -0.001).Close(),
+			want: "M0.00,0.00 L10.13,0.00 Z",
+		}, {
+			name: "curves",
+			path: NewPath().MoveTo(1, 2).CubicTo(3, 4, 5, 6, 7, 8).QuadTo(9, 10, 11, 12),
+			want: "M1.00,2.00 C3.00,4.00 5.00,6.00 7.00,8.00 Q9.00,10.00 11.00,12.00",
+		}, {
+			name: "precision",
+			path: (&Path{Precision: 1}).MoveTo(1.26, 2).ArcTo(5, 5, 45, true, false, 3, 4),
+			want: "M1.3,2.0 A5.0,5.0 45 1 0 3.0,4.0",
+		}, {
+			name: "arc",
+			path: NewPath().Arc(50, 50, 10, 0, 90),
+			want: "M60.00,50.00 A10.00,10.00 0 0 1 50.00,60.00",
+		}, {
+			name: "arc-anticlockwise-large",
+			path: NewPath().Arc(50, 50, 10, 0, -270),
+			want: "M60.00,50.00 A10.00,10.00 0 1 0 50.00,60.00",
+		}, {
+			name: "arc-full-circle",
+			path: NewPath().Arc(0, 0, 10, 0, 360),
+			want: "M10.00,0.00 A10.00,10.00 0 0 1 -10.00,0.00 A10.00,10.00 0 0 1 10.00,0.00",
+		}, {
+			name: "arc-one-and-a-half-turns",
+			path: NewPath().Arc(0, 0, 10, 0, 540),
+			want: "M10.00,0.00 A10.00,10.00 0 1 1 0.00,-10.00 A10.00,10.00 0 1 1 -10.00,0.00",
+		}, {
+			name: "arc-two-turns",
+			path: NewPath().Arc(0, 0, 10, 0, -720),
+			want: "M10.00,0.00 A10.00,10.00 0 1 0 -5.00,8.66 A10.00,10.00 0 1 0 -5.00,-8.66 A10.00,10.00 0 1 0 10.00,0.00",
+		}, {
+			name: "arc-joined",
+			path: NewPath().MoveTo(0, 0).Arc(0, 0, 10, 90, 180),
+			want: "M0.00,0.00 L0.00,10.00 A10.00,10.00 0 0 1 -10.00,0.00",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if got := testcase.path.String(); got != testcase.want {
+				t.Errorf("got  %q\nwant %q", got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestPathToCubics(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		path *Path
+		want string
+	}{
+		{
+			name: "lines",
+			path: NewPath().MoveTo(0, 0).LineTo(10, 0).Close(),
+			want: "M0.00,0.00 L10.00,0.00 Z",
+		}, {
+			name: "quad",
+			path: NewPath().MoveTo(0, 0).QuadTo(3, 3, 6, 0),
+			want: "M0.00,0.00 C2.00,2.00 4.00,2.00 6.00,0.00",
+		}, {
+			name: "quarter-arc",
+			path: NewPath().Arc(0, 0, 10, 0, 90),
+			want: "M10.00,0.00 C10.00,5.52 5.52,10.00 0.00,10.00",
+		}, {
+			name: "anticlockwise-half-arc",
+			path: NewPath().Arc(0, 0, 10, 0, -180),
+			want: "M10.00,0.00 C10.00,-5.52 5.52,-10.00 0.00,-10.00 C-5.52,-10.00 -10.00,-5.52 -10.00,0.00",
+		}, {
+			name: "flat-arc",
+			path: NewPath().MoveTo(0, 0).ArcTo(0, 5, 0, false, true, 10, 0),
+			want: "M0.00,0.00 L10.00,0.00",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if got := testcase.path.ToCubics().String(); got != testcase.want {
+				t.Errorf("got  %q\nwant %q", got, testcase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,410 @@
+// Package pdf writes visualisations as vector pdf documents, with one
+// chart per page and text in the standard pdf fonts
+//
+//	doc := pdf.NewDocument()
+//	doc.AddPage(timeline.TimelineOptions{...})
+//	doc.AddPage(clock.ClockOptions{...})
+//	doc.WriteTo(out)
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/measure"
+)
+
+// capHeight is the height of capitals in ems, used to place text on
+// its dominant baseline
+const capHeight = 0.7
+
+// fontNames maps the families of package measure onto the standard
+// fonts every pdf reader has, in regular and bold
+var fontNames = map[string][2]string{
+	measure.SansSerif.Name: {"Helvetica", "Helvetica-Bold"},
+	measure.Serif.Name:     {"Times-Roman", "Times-Bold"},
+	measure.Monospace.Name: {"Courier", "Courier-Bold"},
+}
+
+type page struct {
+	width, height float64
+	content       bytes.Buffer
+}
+
+// opacity is a graphics state setting the fill and stroke alpha
+type opacity struct {
+	fill, stroke float64
+}
+
+// Document is a pdf being built up a page at a time. it is also a
+// visual.Canvas, where each Start begins a new page
+type Document struct {
+	visual.Static
+	// Compress deflates the drawing commands of each page
+	Compress bool
+	pages    []*page
+	fonts    []string
+	states   []opacity
+}
+
+// NewDocument creates an empty document with compression turned on
+func NewDocument() *Document {
+	return &Document{Compress: true}
+}
+
+// AddPage validates `d` and draws it onto a new page of its preferred
+// size. nothing is added if it is invalid
+func (doc *Document) AddPage(d visual.Drawer) error {
+	return visual.DrawTo(doc, d)
+}
+
+// Write writes `d` as a single page pdf
+func Write(out io.Writer, d visual.Drawer) error {
+	doc := NewDocument()
+	if err := doc.AddPage(d); err != nil {
+		return err
+	}
+	_, err := doc.WriteTo(out)
+	return err
+}
+
+func (doc *Document) page() *page {
+	return doc.pages[len(doc.pages)-1]
+}
+
+func (doc *Document) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&doc.page().content, format, args...)
+}
+
+// Start begins a new page `width` by `height` points, with the origin
+// moved to the top left and y pointing down to match svg
+func (doc *Document) Start(width, height float64) {
+	doc.pages = append(doc.pages, &page{width: width, height: height})
+	doc.printf("1 0 0 -1 0 %s cm 4 M\n", num(height))
+}
+
+func (doc *Document) End() error {
+	return nil
+}
+
+func (doc *Document) Group(id string) {
+	doc.printf("q\n")
+}
+
+func (doc *Document) Translate(x, y float64) {
+	doc.printf("q 1 0 0 1 %s %s cm\n", num(x), num(y))
+}
+
+func (doc *Document) TranslateRotate(x, y, degrees float64) {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	doc.printf("q 1 0 0 1 %s %s cm %s %s %s %s 0 0 cm\n", num(x), num(y),
+		num(cos), num(sin), num(-sin), num(cos))
+}
+
+func (doc *Document) EndGroup() {
+	doc.printf("Q\n")
+}
+
+// Title does nothing, pdf pages have nowhere to show it
+func (doc *Document) Title(text string) {}
+
+func (doc *Document) Line(x1, y1, x2, y2 float64, style visual.Style) {
+	visual.DrawLine(doc, x1, y1, x2, y2, style)
+}
+
+func (doc *Document) Polyline(xs, ys []float64, style visual.Style) {
+	p := visual.NewPath()
+	for i := range xs {
+		if i == 0 {
+			p.MoveTo(xs[i], ys[i])
+		} else {
+			p.LineTo(xs[i], ys[i])
+		}
+	}
+	doc.Path(p, style)
+}
+
+func (doc *Document) Circle(cx, cy, r float64, style visual.Style) {
+	doc.Path(visual.NewPath().Arc(cx, cy, r, 0, 360).Close(), style)
+}
+
+func (doc *Document) Rect(x, y, width, height float64, style visual.Style) {
+	doc.Path(visual.NewPath().MoveTo(x, y).LineTo(x+width, y).
+		LineTo(x+width, y+height).LineTo(x, y+height).Close(), style)
+}
+
+func (doc *Document) Path(p *visual.Path, style visual.Style) {
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	fillColour, filled := parseColour(fill, style.FillOpacity)
+	strokeColour, stroked := parseColour(style.Stroke, style.StrokeOpacity)
+	width := 1.0
+	if style.StrokeWidth != nil {
+		width = *style.StrokeWidth
+	}
+	stroked = stroked && width > 0
+	if !filled && !stroked {
+		return
+	}
+	var ops strings.Builder
+	for _, seg := range p.ToCubics().Segments() {
+		for _, pt := range seg.Points {
+			ops.WriteString(num(pt.X) + " " + num(pt.Y) + " ")
+		}
+		switch seg.Op {
+		case visual.OpMove:
+			ops.WriteString("m\n")
+		case visual.OpLine:
+			ops.WriteString("l\n")
+		case visual.OpCubic:
+			ops.WriteString("c\n")
+		case visual.OpClose:
+			ops.WriteString("h\n")
+		}
+	}
+	state := opacity{fill: 1, stroke: 1}
+	op := "f"
+	doc.printf("q ")
+	if filled {
+		doc.printf("%s rg ", rgb(fillColour))
+		state.fill = fillColour.A
+	}
+	if stroked {
+		doc.printf("%s RG %s w %d J ", rgb(strokeColour), num(width),
+			capStyle(style.StrokeLineCap))
+		state.stroke = strokeColour.A
+		op = "S"
+		if filled {
+			op = "B"
+		}
+	}
+	doc.setOpacity(state)
+	doc.printf("\n%s%s Q\n", ops.String(), op)
+}
+
+// Text draws `text` in the standard font closest to the style's family.
+// characters outside latin-1 are replaced with "?"
+func (doc *Document) Text(x, y float64, text string, style visual.Style) {
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	colour, ok := parseColour(fill, style.FillOpacity)
+	size := 16
+	if style.FontSize != nil {
+		size = *style.FontSize
+	}
+	if !ok || size <= 0 {
+		return
+	}
+	family := measure.Lookup(style.FontFamily)
+	switch style.TextAnchor {
+	case "middle":
+		x -= family.Width(text, size) / 2
+	case "end":
+		x -= family.Width(text, size)
+	}
+	switch style.DominantBaseline {
+	case "central", "middle":
+		y += float64(size) * capHeight / 2
+	case "hanging", "text-before-edge":
+		y += float64(size) * capHeight
+	}
+	bold := 0
+	if w, err := strconv.Atoi(style.FontWeight); style.FontWeight == "bold" ||
+		(err == nil && w >= 600) {
+		bold = 1
+	}
+	font := doc.font(fontNames[family.Name][bold])
+	doc.printf("q %s rg ", rgb(colour))
+	doc.setOpacity(opacity{fill: colour.A, stroke: 1})
+	// the text matrix flips y back so that glyphs are upright
+	doc.printf("BT /F%d %d Tf 1 0 0 -1 %s %s Tm %s Tj ET Q\n",
+		font, size, num(x), num(y), literal(text))
+}
+
+// font returns the number of the resource for the standard font `name`
+func (doc *Document) font(name string) int {
+	for i, f := range doc.fonts {
+		if f == name {
+			return i
+		}
+	}
+	doc.fonts = append(doc.fonts, name)
+	return len(doc.fonts) - 1
+}
+
+// setOpacity selects a graphics state for `o`, fully opaque needs none
+func (doc *Document) setOpacity(o opacity) {
+	if o.fill == 1 && o.stroke == 1 {
+		return
+	}
+	for i, s := range doc.states {
+		if s == o {
+			doc.printf("/GS%d gs ", i)
+			return
+		}
+	}
+	doc.states = append(doc.states, o)
+	doc.printf("/GS%d gs ", len(doc.states)-1)
+}
+
+// WriteTo writes the document as a pdf
+func (doc *Document) WriteTo(out io.Writer) (int64, error) {
+	w := &writer{w: visual.NewErrWriter(out)}
+	w.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	// objects are numbered: catalog, page tree, shared resources, fonts,
+	// graphics states and then a page and its contents for each page
+	const catalog, pages, resources = 1, 2, 3
+	firstFont := resources + 1
+	firstState := firstFont + len(doc.fonts)
+	firstPage := firstState + len(doc.states)
+
+	w.object(catalog, "<< /Type /Catalog /Pages %d 0 R >>", pages)
+	kids := make([]string, len(doc.pages))
+	for i := range doc.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	w.object(pages, "<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(doc.pages))
+
+	var res strings.Builder
+	res.WriteString("<< /ProcSet [/PDF /Text]")
+	if len(doc.fonts) > 0 {
+		res.WriteString(" /Font <<")
+		for i := range doc.fonts {
+			fmt.Fprintf(&res, " /F%d %d 0 R", i, firstFont+i)
+		}
+		res.WriteString(" >>")
+	}
+	if len(doc.states) > 0 {
+		res.WriteString(" /ExtGState <<")
+		for i := range doc.states {
+			fmt.Fprintf(&res, " /GS%d %d 0 R", i, firstState+i)
+		}
+		res.WriteString(" >>")
+	}
+	res.WriteString(" >>")
+	w.object(resources, "%s", res.String())
+	for i, f := range doc.fonts {
+		w.object(firstFont+i, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f)
+	}
+	for i, s := range doc.states {
+		w.object(firstState+i, "<< /Type /ExtGState /ca %s /CA %s >>", num(s.fill), num(s.stroke))
+	}
+	for i, p := range doc.pages {
+		n := firstPage + 2*i
+		w.object(n, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+			pages, num(p.width), num(p.height), resources, n+1)
+		content := p.content.Bytes()
+		filter := ""
+		if doc.Compress {
+			var buf bytes.Buffer
+			z := zlib.NewWriter(&buf)
+			z.Write(content)
+			z.Close()
+			content = buf.Bytes()
+			filter = " /Filter /FlateDecode"
+		}
+		w.offsets = append(w.offsets, w.n)
+		w.printf("%d 0 obj\n<< /Length %d%s >>\nstream\n", n+1, len(content), filter)
+		w.write(content)
+		w.printf("\nendstream\nendobj\n")
+	}
+
+	xref := w.n
+	w.printf("xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		w.printf("%010d 00000 n \n", offset)
+	}
+	w.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, catalog, xref)
+	return w.n, w.w.Err()
+}
+
+// writer counts the bytes written so far, to build the cross reference
+// table of object offsets
+type writer struct {
+	w       *visual.ErrWriter
+	n       int64
+	offsets []int64
+}
+
+func (w *writer) write(p []byte) {
+	n, _ := w.w.Write(p)
+	w.n += int64(n)
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	w.write([]byte(fmt.Sprintf(format, args...)))
+}
+
+// object writes object number `n`, which must be the next number
+func (w *writer) object(n int, format string, args ...interface{}) {
+	w.offsets = append(w.offsets, w.n)
+	w.printf("%d 0 obj\n"+format+"\nendobj\n", append([]interface{}{n}, args...)...)
+}
+
+// num formats a number with up to three decimals
+func num(v float64) string {
+	return visual.FormatFloat(v, 3)
+}
+
+func parseColour(s string, alpha *float64) (visual.Colour, bool) {
+	if s == "" || s == "none" {
+		return visual.Colour{}, false
+	}
+	c, err := visual.ParseColour(s)
+	if err != nil {
+		return visual.Colour{}, false
+	}
+	if alpha != nil {
+		c.A *= math.Max(0, math.Min(1, *alpha))
+	}
+	return c, c.A > 0
+}
+
+func rgb(c visual.Colour) string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+func capStyle(c visual.CapStyle) int {
+	switch c {
+	case visual.CapStyleRound:
+		return 1
+	case visual.CapStyleSquare:
+		return 2
+	}
+	return 0
+}
+
+// literal encodes `s` as a pdf string in the WinAnsi encoding
+func literal(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ':
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/clock"
+	"github.com/osraige/visualisations/timeline"
+	"github.com/osraige/visualisations/visualtest"
+)
+
+// checkXref checks that every object in the cross reference table is
+// at the offset it claims to be
+func checkXref(t *testing.T, doc []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatalf("no startxref at the end of:\n%s", doc)
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	lines := strings.Split(string(doc[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		offset, _ := strconv.Atoi(lines[2+i][:10])
+		want := strconv.Itoa(i) + " 0 obj\n"
+		if !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Errorf("object %d is not at offset %d", i, offset)
+		}
+	}
+}
+
+func TestShapes(t *testing.T) {
+	doc := &Document{}
+	err := doc.AddPage(visualtest.Drawing{Width: 100, Height: 50, Calls: func(c visual.Canvas) {
+		c.TranslateRotate(10, 20, 90)
+		c.Line(0, 0, 10, 0, visual.Style{
+			Stroke:        "red",
+			StrokeWidth:   visual.Float(2),
+			StrokeLineCap: visual.CapStyleRound,
+			StrokeOpacity: visual.Float(0.5),
+		})
+		c.EndGroup()
+		c.Rect(0, 0, 5, 5, visual.Style{Fill: "#0000ff", Stroke: "none"})
+		c.Text(50, 25, "(a) ñ → b", visual.Style{
+			FontFamily:       "Courier",
+			FontSize:         visual.Int(10),
+			FontWeight:       "bold",
+			TextAnchor:       "middle",
+			DominantBaseline: "central",
+		})
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"%PDF-1.4\n",
+		"/MediaBox [0 0 100 50]",
+		"1 0 0 -1 0 50 cm",
+		"q 1 0 0 1 10 20 cm 0 1 -1 0 0 0 cm\n",
+		"1 0 0 RG 2 w 1 J /GS0 gs \n0 0 m\n10 0 l\nS Q\n",
+		"/GS0 5 0 R",
+		"<< /Type /ExtGState /ca 1 /CA 0.5 >>",
+		"0 0 1 rg \n0 0 m\n5 0 l\n5 5 l\n0 5 l\nh\nf Q\n",
+		"/BaseFont /Courier-Bold",
+		`/F0 10 Tf 1 0 0 -1 23 28.5 Tm (\(a\) \361 ? b) Tj`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+	checkXref(t, buf.Bytes())
+}
+
+func TestPages(t *testing.T) {
+	doc := NewDocument()
+	if err := doc.AddPage(clock.ClockOptions{
+		Size:         200,
+		CenterRadius: 40,
+		Segments:     24,
+		DataHands:    []int{20, 40, 60},
+		DataAverage:  []int{50},
+		Theme:        &visual.LightTheme,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.AddPage(timeline.TimelineOptions{
+		SegmentLength: 20,
+		LineWidth:     4,
+		GapHeight:     10,
+		GapWidth:      10,
+		Entries:       [][]string{{"a"}},
+		ColumnLabels:  []string{"one"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.AddPage(clock.ClockOptions{}); err == nil {
+		t.Error("added a page for an invalid clock")
+	}
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"/Count 2",
+		"/MediaBox [0 0 200 200]",
+		"/MediaBox [0 0 20 20]",
+		"/Filter /FlateDecode",
+		"/BaseFont /Helvetica",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q", want)
+		}
+	}
+	checkXref(t, buf.Bytes())
+}
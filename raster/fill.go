@@ -0,0 +1,152 @@
+package raster
+
+import (
+	"image"
+	"math"
+
+	visual "github.com/osraige/visualisations"
+)
+
+// rasterizer turns polygons into an alpha mask. each edge adds the
+// signed area it covers to the cells of the rows it crosses, summing a
+// row from the left then gives the coverage of each pixel under the
+// non-zero winding rule, with anti-aliased edges
+type rasterizer struct {
+	w, h   int
+	stride int
+	area   []float64
+	// rows that have been touched since the last reset
+	minY, maxY int
+}
+
+func newRasterizer(w, h int) *rasterizer {
+	r := &rasterizer{w: w, h: h, stride: w + 2}
+	r.area = make([]float64, r.stride*h)
+	r.minY = h
+	return r
+}
+
+// reset clears the cells touched since the last reset
+func (r *rasterizer) reset() {
+	for y := r.minY; y < r.maxY; y++ {
+		row := r.area[y*r.stride : (y+1)*r.stride]
+		for x := range row {
+			row[x] = 0
+		}
+	}
+	r.minY, r.maxY = r.h, 0
+}
+
+// polygon adds the closed outline of `points`
+func (r *rasterizer) polygon(points []visual.Point) {
+	for i := range points {
+		r.line(points[i], points[(i+1)%len(points)])
+	}
+}
+
+// line adds an edge of a polygon
+func (r *rasterizer) line(p0, p1 visual.Point) {
+	// split the edge where it crosses the sides of the image, the parts
+	// outside can then be flattened onto the sides without changing the
+	// coverage of any pixel inside
+	for _, edge := range []float64{0, float64(r.w)} {
+		if (p0.X < edge) != (p1.X < edge) && p0.X != edge && p1.X != edge {
+			t := (edge - p0.X) / (p1.X - p0.X)
+			mid := visual.Point{X: edge, Y: p0.Y + t*(p1.Y-p0.Y)}
+			r.line(p0, mid)
+			r.line(mid, p1)
+			return
+		}
+	}
+	p0.X = math.Max(0, math.Min(float64(r.w), p0.X))
+	p1.X = math.Max(0, math.Min(float64(r.w), p1.X))
+	r.accumulate(p0, p1)
+}
+
+func (r *rasterizer) accumulate(p0, p1 visual.Point) {
+	if p0.Y == p1.Y || math.IsNaN(p0.Y) || math.IsNaN(p1.Y) {
+		return
+	}
+	dir := 1.0
+	if p0.Y > p1.Y {
+		dir = -1
+		p0, p1 = p1, p0
+	}
+	if p1.Y <= 0 || p0.Y >= float64(r.h) {
+		return
+	}
+	dxdy := (p1.X - p0.X) / (p1.Y - p0.Y)
+	x := p0.X
+	if p0.Y < 0 {
+		x = math.Max(0, math.Min(float64(r.w), x-p0.Y*dxdy))
+	}
+	y0 := int(math.Max(0, p0.Y))
+	y1 := int(math.Min(float64(r.h), math.Ceil(p1.Y)))
+	if y0 < r.minY {
+		r.minY = y0
+	}
+	if y1 > r.maxY {
+		r.maxY = y1
+	}
+	for y := y0; y < y1; y++ {
+		row := r.area[y*r.stride : (y+1)*r.stride]
+		dy := math.Min(float64(y+1), p1.Y) - math.Max(float64(y), p0.Y)
+		// rounding can carry x just past the sides
+		xnext := math.Max(0, math.Min(float64(r.w), x+dxdy*dy))
+		d := dy * dir
+		x0, x1 := x, xnext
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		x0floor := math.Floor(x0)
+		x0i := int(x0floor)
+		x1ceil := math.Ceil(x1)
+		x1i := int(x1ceil)
+		if x1i <= x0i+1 {
+			// the edge stays within one pixel of this row
+			xmf := 0.5*(x+xnext) - x0floor
+			row[x0i] += d - d*xmf
+			row[x0i+1] += d * xmf
+		} else {
+			s := 1 / (x1 - x0)
+			x0f := x0 - x0floor
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := x1 - x1ceil + 1
+			am := 0.5 * s * x1f * x1f
+			row[x0i] += d * a0
+			if x1i == x0i+2 {
+				row[x0i+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - x0f)
+				row[x0i+1] += d * (a1 - a0)
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					row[xi] += d * s
+				}
+				a2 := a1 + float64(x1i-x0i-3)*s
+				row[x1i-1] += d * (1 - a2 - am)
+			}
+			row[x1i] += d * am
+		}
+		x = xnext
+	}
+}
+
+// mask returns the coverage of every touched pixel multiplied by
+// `opacity`, or nil when nothing was covered
+func (r *rasterizer) mask(opacity float64) *image.Alpha {
+	if r.maxY <= r.minY {
+		return nil
+	}
+	bounds := image.Rect(0, r.minY, r.w, r.maxY)
+	mask := image.NewAlpha(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := r.area[y*r.stride : (y+1)*r.stride]
+		acc := 0.0
+		for x := 0; x < r.w; x++ {
+			acc += row[x]
+			coverage := math.Min(1, math.Abs(acc))
+			mask.Pix[mask.PixOffset(x, y)] = uint8(coverage*opacity*255 + 0.5)
+		}
+	}
+	return mask
+}
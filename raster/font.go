@@ -0,0 +1,224 @@
+package raster
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/measure"
+)
+
+// the bundled font is drawn with strokes, so that it stays smooth at
+// any size. glyphs are laid out in a box 10 units wide spanning the
+// advance width that package measure gives each character, with the
+// baseline at 0 and capitals 10 units tall. strokes are separated by
+// ";" and made of "x,y" points or "cx,cy,rx,ry,from,to" elliptical arcs
+// whose angles are in degrees anticlockwise from pointing right
+var glyphSource = map[rune]string{
+	'!':  "5,10 5,3;5,0 5,0.01",
+	'"':  "3.5,10 3.5,7.5;6.5,10 6.5,7.5",
+	'#':  "3.5,10 2,0;7.5,10 6,0;1,6.5 9,6.5;0.5,3.5 8.5,3.5",
+	'$':  "8.5,8 7.5,9.5 5,9.8 2.5,9.5 1.5,8 2.5,6 7.5,4 8.5,2 7.5,0.5 5,0.2 2.5,0.5 1.5,2;5,11 5,-1",
+	'%':  "9,10 1,0;2.5,8,1.5,2,0,360;7.5,2,1.5,2,0,360",
+	'&':  "9,0 3,7 3,9 4.5,10 6,9 6,7.5 1.5,3.5 1.5,1.5 3.5,0 6,0 9,4",
+	'\'': "5,10 5,7.5",
+	'(':  "7,10.5 4.8,7.5 4,4 4.8,0 7,-2.5",
+	')':  "3,10.5 5.2,7.5 6,4 5.2,0 3,-2.5",
+	'*':  "5,10 5,6;3,9 7,7;7,9 3,7",
+	'+':  "5,7 5,1;2,4 8,4",
+	',':  "5.5,0.5 5,0 4,-2",
+	'-':  "2,3.5 8,3.5",
+	'.':  "5,0 5,0.01",
+	'/':  "8,10 2,0",
+	'0':  "5,5,3.8,5,0,360",
+	'1':  "2.5,8 5.5,10 5.5,0;2.5,0 8.5,0",
+	'2':  "1.5,7.5 2,9 3.5,9.9 6.5,9.9 8,9 8.5,7.5 8,6 1.5,0 8.5,0",
+	'3':  "1.5,9.9 8,9.9 4.5,6 6.5,6 8,5 8.5,3 7.8,1 6,0 3.5,0 1.5,1",
+	'4':  "7,0 7,10 1,3 9,3",
+	'5':  "8,10 2,10 1.5,5.5 3.5,6.5 6,6.5 8,5.5 8.5,3.3 7.8,1 6,0 3.5,0 1.5,1",
+	'6':  "7.8,9 6,10 4,10 2.3,8.5 1.5,5.5 1.5,3.2;5,3.2,3.5,3.2,0,360",
+	'7':  "1.5,10 8.5,10 4,0",
+	'8':  "5,7.6,3,2.4,0,360;5,2.7,3.5,2.7,0,360",
+	'9':  "5,6.8,3.5,3.2,0,360;8.5,6.8 8.5,4.5 7.7,1.5 6,0 4,0 2.2,1",
+	':':  "5,7 5,7.01;5,0 5,0.01",
+	';':  "5,7 5,7.01;5.5,0.5 5,0 4,-2",
+	'<':  "8.5,7 1.5,4 8.5,1",
+	'=':  "1.5,5.5 8.5,5.5;1.5,2.5 8.5,2.5",
+	'>':  "1.5,7 8.5,4 1.5,1",
+	'?':  "1.5,8 2.5,9.5 5,10 7.5,9.5 8.5,8 8,6.5 5,5 5,3;5,0 5,0.01",
+	'@':  "5,4.5,2,2.2,0,360;7,6.7 7,3 8.2,2.3 9.3,3.5 9.5,5 9,7.5 7.5,9.3 5,10 2.5,9.3 0.8,7.5 0.5,5 0.8,2.5 2.5,0.7 5,0 7.5,0.5",
+	'A':  "0.5,0 5,10 9.5,0;2.2,3.5 7.8,3.5",
+	'B':  "1.5,0 1.5,10 6,10 7.7,9.3 8.2,7.7 7.7,6.1 6,5.2 1.5,5.2;6,5.2 8,4.5 8.7,2.7 8,0.8 6,0 1.5,0",
+	'C':  "5.3,5,4.3,5,40,320",
+	'D':  "1.5,0 1.5,10 5,10 7.5,9 8.8,7 9,5 8.8,3 7.5,1 5,0 1.5,0",
+	'E':  "8.5,10 1.5,10 1.5,0 8.5,0;1.5,5.2 7.5,5.2",
+	'F':  "8.5,10 1.5,10 1.5,0;1.5,5.2 7.5,5.2",
+	'G':  "5.3,5,4.3,5,40,325 8.8,4.3 5.5,4.3",
+	'H':  "1.5,0 1.5,10;8.5,0 8.5,10;1.5,5.2 8.5,5.2",
+	'I':  "5,0 5,10",
+	'J':  "7,10 7,2.5 6.2,0.6 4.5,0 2.8,0.6 2,2.5",
+	'K':  "1.5,0 1.5,10;8.5,10 1.5,3.5;3.8,5.5 8.8,0",
+	'L':  "1.5,10 1.5,0 8.5,0",
+	'M':  "1.2,0 1.2,10 5,0 8.8,10 8.8,0",
+	'N':  "1.5,0 1.5,10 8.5,0 8.5,10",
+	'O':  "5,5,4,5,0,360",
+	'P':  "1.5,0 1.5,10 6,10 7.8,9.2 8.5,7.5 7.8,5.8 6,5 1.5,5",
+	'Q':  "5,5,4,5,0,360;6,2.5 9,-0.5",
+	'R':  "1.5,0 1.5,10 6,10 7.8,9.2 8.5,7.5 7.8,5.8 6,5 1.5,5;5.5,5 8.7,0",
+	'S':  "8.5,8.5 7,9.7 5,10 3,9.7 1.6,8.5 1.6,6.8 3,5.8 7,4.3 8.4,3.2 8.4,1.4 7,0.3 5,0 3,0.3 1.3,1.5",
+	'T':  "0.5,10 9.5,10;5,10 5,0",
+	'U':  "1.5,10 1.5,3 2.3,1 5,0 7.7,1 8.5,3 8.5,10",
+	'V':  "0.5,10 5,0 9.5,10",
+	'W':  "0.3,10 2.6,0 5,8 7.4,0 9.7,10",
+	'X':  "1,10 9,0;9,10 1,0",
+	'Y':  "0.5,10 5,5 9.5,10;5,5 5,0",
+	'Z':  "1.5,10 8.5,10 1.5,0 8.5,0",
+	'[':  "7,11 4.5,11 4.5,-2.5 7,-2.5",
+	'\\': "2,10 8,0",
+	']':  "3,11 5.5,11 5.5,-2.5 3,-2.5",
+	'^':  "2,6 5,10 8,6",
+	'_':  "0,-2 10,-2",
+	'`':  "4,10.5 6,8.5",
+	'a':  "2,6.3 3.5,7 6,7 7.5,6 7.5,1 8.3,0;7.5,4 3.5,3.5 1.8,2.5 1.8,1 3,0 5,0 7.5,1.5",
+	'b':  "1.5,10 1.5,0;5.2,3.5,3.4,3.5,0,360",
+	'c':  "5.2,3.5,3.5,3.5,45,315",
+	'd':  "8.5,10 8.5,0;4.8,3.5,3.4,3.5,0,360",
+	'e':  "1.5,3.7 8.5,3.7 5,3.5,3.5,3.5,5,320",
+	'f':  "7.5,9.5 6.5,10 5,10 4,9 4,0;1.5,7 7,7",
+	'g':  "8.5,7 8.5,-1 7.7,-2.6 5,-3 2.3,-2.6;4.8,3.7,3.4,3.4,0,360",
+	'h':  "1.5,10 1.5,0;1.5,4.5 2.8,6.3 4.5,7 6.5,7 8,6 8.5,4.5 8.5,0",
+	'i':  "5,7 5,0;5,9.5 5,9.51",
+	'j':  "5.5,7 5.5,-1.5 5,-2.7 3.5,-3;5.5,9.5 5.5,9.51",
+	'k':  "2,10 2,0;8,7 2,2.5;4.2,4.2 8.5,0",
+	'l':  "5,10 5,0",
+	'm':  "1,7 1,0;1,5 2.2,6.6 3.5,7 4.7,6.5 5,5 5,0;5,5 6.2,6.6 7.5,7 8.7,6.5 9,5 9,0",
+	'n':  "1.5,7 1.5,0;1.5,4.5 2.8,6.3 4.5,7 6.5,7 8,6 8.5,4.5 8.5,0",
+	'o':  "5,3.5,3.5,3.5,0,360",
+	'p':  "1.5,7 1.5,-3;5.2,3.5,3.4,3.5,0,360",
+	'q':  "8.5,7 8.5,-3;4.8,3.5,3.4,3.5,0,360",
+	'r':  "2,7 2,0;2,4 3.5,6.3 5.5,7 7.5,7",
+	's':  "8,5.8 6.5,6.8 5,7 3.3,6.8 2,5.8 2.2,4.4 4,3.7 6.5,3.2 8,2.2 8,1 6.5,0.2 5,0 3.2,0.2 1.7,1.2",
+	't':  "4.5,9 4.5,1 5.2,0 7,0;1.5,7 7,7",
+	'u':  "1.5,7 1.5,2.5 2,1 3.5,0 5.5,0 7.2,1 8.5,2.5;8.5,7 8.5,0",
+	'v':  "1,7 5,0 9,7",
+	'w':  "0.5,7 2.7,0 5,6 7.3,0 9.5,7",
+	'x':  "1.5,7 8.5,0;8.5,7 1.5,0",
+	'y':  "1,7 5,0;9,7 4,-2 3,-3 1.5,-3",
+	'z':  "1.5,7 8.5,7 1.5,0 8.5,0",
+	'{':  "7,11 5.5,10.5 5,9 5,5.5 3.5,4.25 5,3 5,-0.5 5.5,-2 7,-2.5",
+	'|':  "5,11 5,-3",
+	'}':  "3,11 4.5,10.5 5,9 5,5.5 6.5,4.25 5,3 5,-0.5 4.5,-2 3,-2.5",
+	'~':  "1.5,4 3,5 5,4 7,3 8.5,4",
+}
+
+// missingGlyph is drawn for characters the font doesn't have
+var missingGlyph = parseGlyph("1,0 9,0 9,10 1,10 1,0")
+
+var glyphs = map[rune][][]visual.Point{}
+
+func init() {
+	for r, src := range glyphSource {
+		glyphs[r] = parseGlyph(src)
+	}
+}
+
+func parseGlyph(src string) [][]visual.Point {
+	var strokes [][]visual.Point
+	for _, s := range strings.Split(src, ";") {
+		var points []visual.Point
+		for _, token := range strings.Fields(s) {
+			var v []float64
+			for _, f := range strings.Split(token, ",") {
+				n, err := strconv.ParseFloat(f, 64)
+				if err != nil {
+					panic("raster: bad glyph " + src)
+				}
+				v = append(v, n)
+			}
+			if len(v) == 2 {
+				points = append(points, visual.Point{X: v[0], Y: v[1]})
+				continue
+			}
+			// an arc, sampled every 10 degrees
+			cx, cy, rx, ry, from, to := v[0], v[1], v[2], v[3], v[4], v[5]
+			n := int(math.Ceil(math.Abs(to-from) / 10))
+			for i := 0; i <= n; i++ {
+				a := (from + (to-from)*float64(i)/float64(n)) * math.Pi / 180
+				points = append(points, visual.Point{
+					X: cx + rx*math.Cos(a),
+					Y: cy + ry*math.Sin(a),
+				})
+			}
+		}
+		strokes = append(strokes, points)
+	}
+	return strokes
+}
+
+const (
+	// capHeight is the height of capitals in ems
+	capHeight = 0.7
+	// sideBearing is the space either side of a glyph as a proportion
+	// of its advance
+	sideBearing = 0.08
+	// strokes are this many ems wide, or boldWeight for bold text
+	normalWeight = 0.075
+	boldWeight   = 0.11
+)
+
+// Text draws `text` in the bundled font, sized and spaced with the
+// metrics package measure has for the style's font family
+func (c *Canvas) Text(x, y float64, text string, style visual.Style) {
+	size := 16
+	if style.FontSize != nil {
+		size = *style.FontSize
+	}
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	col, ok := paintColour(fill, style.FillOpacity)
+	if !ok || size <= 0 {
+		return
+	}
+	em := float64(size)
+	family := measure.Lookup(style.FontFamily)
+	switch style.TextAnchor {
+	case "middle":
+		x -= family.Width(text, size) / 2
+	case "end":
+		x -= family.Width(text, size)
+	}
+	switch style.DominantBaseline {
+	case "central", "middle":
+		y += em * capHeight / 2
+	case "hanging", "text-before-edge":
+		y += em * capHeight
+	}
+	weight := normalWeight
+	if w, err := strconv.Atoi(style.FontWeight); style.FontWeight == "bold" ||
+		(err == nil && w >= 600) {
+		weight = boldWeight
+	}
+	m := c.top()
+	for _, r := range text {
+		advance := family.Width(string(r), size)
+		strokes, ok := glyphs[r]
+		if !ok && r != ' ' {
+			strokes = missingGlyph
+		}
+		for _, s := range strokes {
+			line := polyline{points: make([]visual.Point, len(s))}
+			for i, p := range s {
+				line.points[i] = m.apply(visual.Point{
+					X: x + advance*(sideBearing+p.X*(1-2*sideBearing)/10),
+					Y: y - p.Y*em*capHeight/10,
+				})
+			}
+			stroke(c.ras, line, weight*em*m.scale(), visual.CapStyleRound)
+		}
+		x += advance
+	}
+	c.composite(col)
+}
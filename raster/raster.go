@@ -0,0 +1,231 @@
+// Package raster draws visualisations into images, for places that
+// can't show svg such as chat notifications and email clients. it is
+// pure go, with text drawn in a bundled stroke font
+//
+//	err := raster.PNG(out, gauge.GaugeOptions{...}, 2)
+package raster
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	visual "github.com/osraige/visualisations"
+)
+
+// matrix is an affine transform, in the same order as the svg matrix
+// transform
+type matrix struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = matrix{a: 1, d: 1}
+
+func (m matrix) apply(p visual.Point) visual.Point {
+	return visual.Point{
+		X: m.a*p.X + m.c*p.Y + m.e,
+		Y: m.b*p.X + m.d*p.Y + m.f,
+	}
+}
+
+// mul returns the transform that applies `n` and then `m`
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		e: m.a*n.e + m.c*n.f + m.e,
+		f: m.b*n.e + m.d*n.f + m.f,
+	}
+}
+
+// scale is how much the transform grows lengths, it only ever
+// translates, rotates and scales evenly
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m.a*m.d - m.b*m.c))
+}
+
+// Canvas is a visual.Canvas that draws into an image
+type Canvas struct {
+	visual.Static
+	// Scale multiplies the size of the image, such as 2 for screens
+	// with a high pixel density
+	Scale float64
+	img   *image.RGBA
+	ras   *rasterizer
+	stack []matrix
+}
+
+// NewCanvas creates a canvas drawing at `scale` times the size of the
+// visualisation. `scale` must be finite and positive, which Image and
+// PNG check for
+func NewCanvas(scale float64) *Canvas {
+	return &Canvas{Scale: scale}
+}
+
+// Image returns the image drawn so far
+func (c *Canvas) Image() *image.RGBA {
+	return c.img
+}
+
+// ToImage returns where the point `x`, `y` of the current group lands
+// in the image
+func (c *Canvas) ToImage(x, y float64) (float64, float64) {
+	p := c.top().apply(visual.Point{X: x, Y: y})
+	return p.X, p.Y
+}
+
+func (c *Canvas) Start(width, height float64) {
+	w := int(math.Ceil(width * c.Scale))
+	h := int(math.Ceil(height * c.Scale))
+	c.img = image.NewRGBA(image.Rect(0, 0, w, h))
+	c.ras = newRasterizer(w, h)
+	c.stack = []matrix{{a: c.Scale, d: c.Scale}}
+}
+
+func (c *Canvas) End() error {
+	return nil
+}
+
+func (c *Canvas) top() matrix {
+	return c.stack[len(c.stack)-1]
+}
+
+func (c *Canvas) push(m matrix) {
+	c.stack = append(c.stack, c.top().mul(m))
+}
+
+func (c *Canvas) Group(id string) {
+	c.push(identity)
+}
+
+func (c *Canvas) Translate(x, y float64) {
+	c.push(matrix{a: 1, d: 1, e: x, f: y})
+}
+
+func (c *Canvas) TranslateRotate(x, y, degrees float64) {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	c.push(matrix{a: cos, b: sin, c: -sin, d: cos, e: x, f: y})
+}
+
+func (c *Canvas) EndGroup() {
+	if len(c.stack) > 1 {
+		c.stack = c.stack[:len(c.stack)-1]
+	}
+}
+
+// Title does nothing, images have nowhere to show it
+func (c *Canvas) Title(text string) {}
+
+func (c *Canvas) Line(x1, y1, x2, y2 float64, style visual.Style) {
+	visual.DrawLine(c, x1, y1, x2, y2, style)
+}
+
+func (c *Canvas) Polyline(xs, ys []float64, style visual.Style) {
+	p := visual.NewPath()
+	for i := range xs {
+		if i == 0 {
+			p.MoveTo(xs[i], ys[i])
+		} else {
+			p.LineTo(xs[i], ys[i])
+		}
+	}
+	c.Path(p, style)
+}
+
+func (c *Canvas) Path(p *visual.Path, style visual.Style) {
+	c.paint(flatten(p, c.top()), style)
+}
+
+func (c *Canvas) Circle(cx, cy, r float64, style visual.Style) {
+	c.Path(visual.NewPath().Arc(cx, cy, r, 0, 360).Close(), style)
+}
+
+func (c *Canvas) Rect(x, y, width, height float64, style visual.Style) {
+	c.Path(visual.NewPath().MoveTo(x, y).LineTo(x+width, y).
+		LineTo(x+width, y+height).LineTo(x, y+height).Close(), style)
+}
+
+// paint fills and then strokes `lines` as svg would with `style`
+func (c *Canvas) paint(lines []polyline, style visual.Style) {
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	if col, ok := paintColour(fill, style.FillOpacity); ok {
+		for _, l := range lines {
+			c.ras.polygon(l.points)
+		}
+		c.composite(col)
+	}
+	width := 1.0
+	if style.StrokeWidth != nil {
+		width = *style.StrokeWidth
+	}
+	width *= c.top().scale()
+	if col, ok := paintColour(style.Stroke, style.StrokeOpacity); ok && width > 0 {
+		for _, l := range lines {
+			stroke(c.ras, l, width, style.StrokeLineCap)
+		}
+		c.composite(col)
+	}
+}
+
+// composite draws `col` through the coverage of the rasterizer and
+// clears it for the next shape
+func (c *Canvas) composite(col color.NRGBA) {
+	if mask := c.ras.mask(1); mask != nil {
+		draw.DrawMask(c.img, mask.Rect, image.NewUniform(col),
+			image.Point{}, mask, mask.Rect.Min, draw.Over)
+	}
+	c.ras.reset()
+}
+
+// paintColour parses a css colour with an opacity, reporting false for
+// colours that draw nothing
+func paintColour(s string, opacity *float64) (color.NRGBA, bool) {
+	if s == "" || s == "none" {
+		return color.NRGBA{}, false
+	}
+	col, err := visual.ParseColour(s)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	if opacity != nil {
+		col.A *= math.Max(0, math.Min(1, *opacity))
+	}
+	if col.A == 0 {
+		return color.NRGBA{}, false
+	}
+	r, g, b, a := col.RGBA8()
+	return color.NRGBA{R: r, G: g, B: b, A: a}, true
+}
+
+// errScale is returned for a scale that gives no sensible image size
+var errScale = errors.New("raster: scale must be finite and positive")
+
+// Image draws `d` into an image at `scale` times its preferred size
+func Image(d visual.Drawer, scale float64) (*image.RGBA, error) {
+	if !(scale > 0) || math.IsInf(scale, 0) {
+		return nil, errScale
+	}
+	c := NewCanvas(scale)
+	if err := visual.DrawTo(c, d); err != nil {
+		return nil, err
+	}
+	return c.Image(), nil
+}
+
+// PNG draws `d` as a png at `scale` times its preferred size
+func PNG(out io.Writer, d visual.Drawer, scale float64) error {
+	img, err := Image(d, scale)
+	if err != nil {
+		return err
+	}
+	return png.Encode(out, img)
+}
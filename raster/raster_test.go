@@ -0,0 +1,258 @@
+package raster
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/clock"
+	"github.com/osraige/visualisations/gauge"
+	"github.com/osraige/visualisations/timeline"
+	"github.com/osraige/visualisations/visualtest"
+)
+
+func alphaAt(img *image.RGBA, x, y int) uint8 {
+	return img.RGBAAt(x, y).A
+}
+
+func TestRasterizerCoverage(t *testing.T) {
+	r := newRasterizer(10, 10)
+	r.polygon([]visual.Point{{X: 2, Y: 2}, {X: 6.5, Y: 2}, {X: 6.5, Y: 6}, {X: 2, Y: 6}})
+	mask := r.mask(1)
+	for _, testcase := range []struct {
+		x, y int
+		want uint8
+	}{
+		{x: 1, y: 3, want: 0},
+		{x: 2, y: 3, want: 255},
+		{x: 5, y: 5, want: 255},
+		{x: 6, y: 3, want: 128},
+		{x: 7, y: 3, want: 0},
+		{x: 3, y: 6, want: 0},
+	} {
+		if got := mask.AlphaAt(testcase.x, testcase.y).A; got != testcase.want {
+			t.Errorf("coverage at %d,%d = %d, want %d", testcase.x, testcase.y, got, testcase.want)
+		}
+	}
+	r.reset()
+	if mask := r.mask(1); mask != nil {
+		t.Errorf("mask after reset covers %v", mask.Rect)
+	}
+}
+
+func TestRasterizerClipping(t *testing.T) {
+	r := newRasterizer(4, 4)
+	// a triangle poking out of every side still covers the middle
+	r.polygon([]visual.Point{{X: -10, Y: -10}, {X: 20, Y: 2}, {X: -10, Y: 20}})
+	mask := r.mask(1)
+	if got := mask.AlphaAt(1, 2).A; got != 255 {
+		t.Errorf("coverage inside the clipped triangle = %d, want 255", got)
+	}
+}
+
+func TestCaps(t *testing.T) {
+	for _, testcase := range []struct {
+		cap     visual.CapStyle
+		covered bool
+	}{
+		{cap: visual.CapStyleButt, covered: false},
+		{cap: visual.CapStyleRound, covered: true},
+		{cap: visual.CapStyleSquare, covered: true},
+	} {
+		t.Run(string(testcase.cap), func(t *testing.T) {
+			img, err := Image(visualtest.Drawing{Width: 40, Height: 20, Calls: func(c visual.Canvas) {
+				c.Line(10, 10, 30, 10, visual.Style{
+					Stroke:        "black",
+					StrokeWidth:   visual.Float(8),
+					StrokeLineCap: testcase.cap,
+				})
+			}}, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := alphaAt(img, 20, 10); got != 255 {
+				t.Errorf("middle of the line has alpha %d, want 255", got)
+			}
+			// just past the end of the line, on its centre
+			if got := alphaAt(img, 31, 10) > 0; got != testcase.covered {
+				t.Errorf("past the end of the line covered = %v, want %v", got, testcase.covered)
+			}
+		})
+	}
+}
+
+func TestLineFill(t *testing.T) {
+	// svg never fills a line, whatever its style
+	img, err := Image(visualtest.Drawing{Width: 20, Height: 20, Calls: func(c visual.Canvas) {
+		c.Line(2, 2, 18, 18, visual.Style{
+			Fill:        "red",
+			Stroke:      "blue",
+			StrokeWidth: visual.Float(4),
+		})
+	}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			if got := img.RGBAAt(x, y); got.R > 0 {
+				t.Fatalf("%d,%d = %v, want no fill", x, y, got)
+			}
+		}
+	}
+	if got := img.RGBAAt(10, 10); got != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("middle of the line = %v, want blue", got)
+	}
+}
+
+func TestOpacityAndScale(t *testing.T) {
+	img, err := Image(visualtest.Drawing{Width: 10, Height: 10, Calls: func(c visual.Canvas) {
+		c.Rect(0, 0, 10, 10, visual.Style{Fill: "white"})
+		c.Translate(5, 0)
+		c.Rect(0, 0, 5, 10, visual.Style{Fill: "black", FillOpacity: visual.Float(0.5)})
+		c.EndGroup()
+	}}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds(); got != image.Rect(0, 0, 30, 30) {
+		t.Fatalf("bounds = %v, want 30x30", got)
+	}
+	if got := img.RGBAAt(5, 5); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("left half = %v, want white", got)
+	}
+	if got := img.RGBAAt(25, 5); got.R < 126 || got.R > 129 || got.A != 255 {
+		t.Errorf("right half = %v, want mid grey", got)
+	}
+}
+
+func TestArcsAndText(t *testing.T) {
+	img, err := Image(visualtest.Drawing{Width: 100, Height: 100, Calls: func(c visual.Canvas) {
+		c.Path(visual.NewPath().Arc(50, 50, 40, 0, 90), visual.Style{
+			Fill:        "none",
+			Stroke:      "red",
+			StrokeWidth: visual.Float(4),
+		})
+		c.Text(50, 50, "I", visual.Style{
+			Fill:             "blue",
+			FontSize:         visual.Int(40),
+			TextAnchor:       "middle",
+			DominantBaseline: "central",
+		})
+	}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the arc runs clockwise from pointing right to pointing down
+	x, y := visual.PointOnCircum(50, 50, 40, 45)
+	if got := img.RGBAAt(int(x), int(y)); got.R != 255 || got.A != 255 {
+		t.Errorf("middle of the arc = %v, want red", got)
+	}
+	if got := img.RGBAAt(int(x)-10, int(y)+10); got.A != 0 {
+		t.Errorf("outside the arc = %v, want transparent", got)
+	}
+	// the capital I is a vertical stroke centred on the anchor
+	if got := img.RGBAAt(50, 48); got.B != 255 || got.A != 255 {
+		t.Errorf("text stem = %v, want blue", got)
+	}
+	if got := img.RGBAAt(50, 30); got.A != 0 {
+		t.Errorf("above the text = %v, want transparent", got)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	err := PNG(&buf, visualtest.Drawing{Width: 20, Height: 10, Calls: func(c visual.Canvas) {
+		c.Circle(5, 5, 4, visual.Style{Fill: "#00ff00"})
+	}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(40, 20) {
+		t.Errorf("size = %v, want 40x20", got)
+	}
+	if r, g, b, a := img.At(10, 10).RGBA(); r != 0 || g != 0xffff || b != 0 || a != 0xffff {
+		t.Errorf("centre of the circle = %v, want green", img.At(10, 10))
+	}
+}
+
+func TestInvalidScale(t *testing.T) {
+	d := visualtest.Drawing{Width: 20, Height: 10, Calls: func(c visual.Canvas) {}}
+	for _, scale := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if err := PNG(&bytes.Buffer{}, d, scale); err != errScale {
+			t.Errorf("scale %v: got %v, want %v", scale, err, errScale)
+		}
+	}
+}
+
+func TestCharts(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		chart  visual.Drawer
+		width  int
+		height int
+	}{
+		{
+			name: "gauge",
+			chart: gauge.GaugeOptions{
+				Size:           100,
+				Padding:        10,
+				LineWidth:      8,
+				FillProportion: 0.5,
+				Label:          "50%",
+				Theme:          &visual.LightTheme,
+			},
+			width:  200,
+			height: 200,
+		}, {
+			name: "clock",
+			chart: clock.ClockOptions{
+				Size:         100,
+				CenterRadius: 20,
+				Segments:     24,
+				DataHands:    []int{50},
+				DataAverage:  []int{20, 80},
+				Theme:        &visual.DarkTheme,
+			},
+			width:  200,
+			height: 200,
+		}, {
+			name: "timeline",
+			chart: timeline.TimelineOptions{
+				SegmentLength: 20,
+				LineWidth:     4,
+				GapHeight:     10,
+				GapWidth:      10,
+				PaddingX:      10,
+				PaddingY:      10,
+				Entries:       [][]string{{"a", "b"}, {"b"}},
+				ColumnLabels:  []string{"one", "two"},
+				Theme:         &visual.LightTheme,
+			},
+			width:  140,
+			height: 100,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			img, err := Image(testcase.chart, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds().Size(); got != image.Pt(testcase.width, testcase.height) {
+				t.Errorf("size = %v, want %dx%d", got, testcase.width, testcase.height)
+			}
+			// every chart has a theme background
+			if got := img.RGBAAt(0, 0).A; got != 255 {
+				t.Errorf("corner has alpha %d, want an opaque background", got)
+			}
+		})
+	}
+}
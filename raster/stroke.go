@@ -0,0 +1,230 @@
+package raster
+
+import (
+	"math"
+
+	visual "github.com/osraige/visualisations"
+)
+
+// tolerance is the furthest in pixels a flattened curve strays from
+// the real one
+const tolerance = 0.1
+
+// miterLimit is the svg default ratio of miter length to stroke width
+// past which joins are bevelled
+const miterLimit = 4
+
+// polyline is a flattened subpath
+type polyline struct {
+	points []visual.Point
+	closed bool
+}
+
+// flatten converts `p` into polylines in device space using `m`
+func flatten(p *visual.Path, m matrix) []polyline {
+	var lines []polyline
+	var cur *polyline
+	var start visual.Point
+	// begin starts a new polyline at `pt` unless one is in progress
+	begin := func(pt visual.Point) {
+		if cur == nil {
+			lines = append(lines, polyline{points: []visual.Point{pt}})
+			cur = &lines[len(lines)-1]
+		}
+	}
+	for _, seg := range p.ToCubics().Segments() {
+		switch seg.Op {
+		case visual.OpMove:
+			start = m.apply(seg.Points[0])
+			cur = nil
+			begin(start)
+		case visual.OpLine:
+			begin(start)
+			cur.points = append(cur.points, m.apply(seg.Points[0]))
+		case visual.OpCubic:
+			begin(start)
+			p0 := cur.points[len(cur.points)-1]
+			cur.points = cubic(cur.points, p0, m.apply(seg.Points[0]),
+				m.apply(seg.Points[1]), m.apply(seg.Points[2]))
+		case visual.OpClose:
+			begin(start)
+			cur.closed = true
+			cur = nil
+		}
+	}
+	// a move on its own draws nothing, not even caps
+	drawn := lines[:0]
+	for _, l := range lines {
+		if len(l.points) > 1 || l.closed {
+			drawn = append(drawn, l)
+		}
+	}
+	return drawn
+}
+
+// cubic appends the flattened bezier from `p0` to `p3` onto `points`
+func cubic(points []visual.Point, p0, p1, p2, p3 visual.Point) []visual.Point {
+	length := dist(p0, p1) + dist(p1, p2) + dist(p2, p3)
+	n := int(math.Ceil(math.Sqrt(length / tolerance / 4)))
+	if n < 1 {
+		n = 1
+	}
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		points = append(points, visual.Point{
+			X: a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+			Y: a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+		})
+	}
+	return points
+}
+
+// circle returns the outline of a circle in device space
+func circle(c visual.Point, r float64) []visual.Point {
+	n := 8
+	if r > tolerance {
+		n = int(math.Ceil(math.Pi / math.Acos(1-tolerance/r)))
+	}
+	if n < 8 {
+		n = 8
+	} else if n > 512 {
+		n = 512
+	}
+	points := make([]visual.Point, n)
+	for i := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		points[i] = visual.Point{X: c.X + r*cos, Y: c.Y + r*sin}
+	}
+	return points
+}
+
+func dist(a, b visual.Point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
+
+// area is the signed area of a polygon, positive when clockwise in
+// device space
+func area(points []visual.Point) float64 {
+	sum := 0.0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		sum += p.X*q.Y - q.X*p.Y
+	}
+	return sum / 2
+}
+
+// piece adds a polygon that is part of a stroke. every piece is wound
+// the same way so that where pieces overlap their coverage adds up
+// rather than cancelling out
+func piece(r *rasterizer, points ...visual.Point) {
+	if area(points) < 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	r.polygon(points)
+}
+
+// stroke adds the outline of `line` drawn `width` pixels wide
+func stroke(r *rasterizer, line polyline, width float64, cap visual.CapStyle) {
+	hw := width / 2
+	// drop repeated points, which have no direction
+	points := make([]visual.Point, 0, len(line.points))
+	for _, p := range line.points {
+		if len(points) == 0 || p != points[len(points)-1] {
+			points = append(points, p)
+		}
+	}
+	if line.closed && len(points) > 2 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	if len(points) == 0 {
+		return
+	}
+	if len(points) == 1 {
+		// zero length lines still draw their caps
+		p := points[0]
+		switch cap {
+		case visual.CapStyleRound:
+			piece(r, circle(p, hw)...)
+		case visual.CapStyleSquare:
+			piece(r,
+				visual.Point{X: p.X - hw, Y: p.Y - hw},
+				visual.Point{X: p.X + hw, Y: p.Y - hw},
+				visual.Point{X: p.X + hw, Y: p.Y + hw},
+				visual.Point{X: p.X - hw, Y: p.Y + hw})
+		}
+		return
+	}
+	n := len(points) - 1
+	if line.closed {
+		n = len(points)
+	}
+	for i := 0; i < n; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		dx, dy := unit(a, b)
+		// extend the ends of open lines with square caps
+		if !line.closed && cap == visual.CapStyleSquare {
+			if i == 0 {
+				a = visual.Point{X: a.X - dx*hw, Y: a.Y - dy*hw}
+			}
+			if i == n-1 {
+				b = visual.Point{X: b.X + dx*hw, Y: b.Y + dy*hw}
+			}
+		}
+		nx, ny := -dy*hw, dx*hw
+		piece(r,
+			visual.Point{X: a.X + nx, Y: a.Y + ny},
+			visual.Point{X: b.X + nx, Y: b.Y + ny},
+			visual.Point{X: b.X - nx, Y: b.Y - ny},
+			visual.Point{X: a.X - nx, Y: a.Y - ny})
+	}
+	for i := range points {
+		if !line.closed && (i == 0 || i == len(points)-1) {
+			continue
+		}
+		prev := points[(i+len(points)-1)%len(points)]
+		next := points[(i+1)%len(points)]
+		join(r, prev, points[i], next, hw)
+	}
+	if !line.closed && cap == visual.CapStyleRound {
+		piece(r, circle(points[0], hw)...)
+		piece(r, circle(points[len(points)-1], hw)...)
+	}
+}
+
+// join fills the gap on the outside of the corner at `p`, mitred up to
+// the miter limit and bevelled past it
+func join(r *rasterizer, prev, p, next visual.Point, hw float64) {
+	d0x, d0y := unit(prev, p)
+	d1x, d1y := unit(p, next)
+	cross := d0x*d1y - d0y*d1x
+	if cross == 0 {
+		return
+	}
+	// normals on the outside of the turn
+	side := -1.0
+	if cross < 0 {
+		side = 1
+	}
+	n0 := visual.Point{X: p.X - d0y*hw*side, Y: p.Y + d0x*hw*side}
+	n1 := visual.Point{X: p.X - d1y*hw*side, Y: p.Y + d1x*hw*side}
+	// the miter tip is along the bisector of the two normals
+	cos := d0x*d1x + d0y*d1y
+	miter := 1 / math.Sqrt((1+cos)/2)
+	if miter > miterLimit || math.IsInf(miter, 0) {
+		piece(r, p, n0, n1)
+		return
+	}
+	mx, my := (n0.X+n1.X)/2-p.X, (n0.Y+n1.Y)/2-p.Y
+	l := math.Hypot(mx, my)
+	tip := visual.Point{X: p.X + mx/l*hw*miter, Y: p.Y + my/l*hw*miter}
+	piece(r, p, n0, tip, n1)
+}
+
+func unit(a, b visual.Point) (float64, float64) {
+	d := dist(a, b)
+	return (b.X - a.X) / d, (b.Y - a.Y) / d
+}
@@ -0,0 +1,81 @@
+package visualisations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Renderer is a visualisation that can draw itself as an svg
+type Renderer interface {
+	Render(out io.Writer) error
+	// PreferredSize returns the width and height the visualisation is
+	// drawn at
+	PreferredSize() (width, height float64)
+}
+
+// Decoder decodes encoded options into `v`, such as a json.Unmarshal
+// bound to some data
+type Decoder func(v interface{}) error
+
+// Factory creates a Renderer from options read with `decode`
+type Factory func(decode Decoder) (Renderer, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a visualisation available by `name` to New. packages
+// providing visualisations register themselves when imported, so
+// callers only need a blank import. it panics if `name` is already
+// registered or `factory` is nil
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("visualisations: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("visualisations: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Registered returns the sorted names of every registered visualisation
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the visualisation registered as `name`, reading its
+// options with `decode`
+func New(name string, decode Decoder) (Renderer, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("visualisations: unknown visualisation %q", name)
+	}
+	r, err := factory(decode)
+	if err != nil {
+		return nil, fmt.Errorf("visualisations: decoding %s options: %w", name, err)
+	}
+	return r, nil
+}
+
+// NewFromJSON creates the visualisation registered as `name` from its
+// options encoded as json
+func NewFromJSON(name string, data []byte) (Renderer, error) {
+	return New(name, func(v interface{}) error {
+		return json.Unmarshal(data, v)
+	})
+}
@@ -0,0 +1,58 @@
+package visualisations
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+type fakeRenderer struct {
+	Width float64
+}
+
+func (f fakeRenderer) Render(out io.Writer) error {
+	return nil
+}
+
+func (f fakeRenderer) PreferredSize() (float64, float64) {
+	return f.Width, f.Width
+}
+
+func TestRegistry(t *testing.T) {
+	Register("test-fake", func(decode Decoder) (Renderer, error) {
+		f := fakeRenderer{}
+		err := decode(&f)
+		return f, err
+	})
+	found := false
+	for _, name := range Registered() {
+		found = found || name == "test-fake"
+	}
+	if !found {
+		t.Errorf("Registered() = %v, missing test-fake", Registered())
+	}
+
+	r, err := NewFromJSON("test-fake", []byte(`{"Width": 42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, _ := r.PreferredSize(); w != 42 {
+		t.Errorf("PreferredSize() width = %v, want 42", w)
+	}
+
+	if _, err := NewFromJSON("test-missing", nil); err == nil {
+		t.Error("NewFromJSON() of an unknown name succeeded")
+	}
+	var syntaxErr *json.SyntaxError
+	if _, err := NewFromJSON("test-fake", []byte(`{`)); !errors.As(err, &syntaxErr) {
+		t.Errorf("NewFromJSON() of bad json = %v, want a wrapped syntax error", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name did not panic")
+		}
+	}()
+	Register("test-fake", func(Decoder) (Renderer, error) { return nil, nil })
+}
@@ -0,0 +1,74 @@
+package scale
+
+import "math"
+
+// Band divides the range into evenly sized bands, one for each value
+// of the domain, like the columns of a bar chart
+type Band struct {
+	Domain []string
+	Range  [2]float64
+	// PaddingInner is the proportion of each step left empty between
+	// bands, between 0 and 1
+	PaddingInner float64
+	// PaddingOuter is the space before the first and after the last
+	// band, as a proportion of a step
+	PaddingOuter float64
+	// Align positions the bands within any outer space, 0.5 centres them
+	Align float64
+}
+
+func (s Band) index(v string) int {
+	for i, d := range s.Domain {
+		if d == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// Step is the distance between the starts of adjacent bands
+func (s Band) Step() float64 {
+	n := float64(len(s.Domain))
+	return (s.Range[1] - s.Range[0]) /
+		math.Max(1, n-s.PaddingInner+2*s.PaddingOuter)
+}
+
+// Bandwidth is the size of each band
+func (s Band) Bandwidth() float64 {
+	return s.Step() * (1 - s.PaddingInner)
+}
+
+// Map returns the start of the band for `v`, and false if `v` is not
+// in the domain
+func (s Band) Map(v string) (float64, bool) {
+	i := s.index(v)
+	if i < 0 {
+		return 0, false
+	}
+	step := s.Step()
+	n := float64(len(s.Domain))
+	span := s.Range[1] - s.Range[0]
+	start := s.Range[0] + (span-step*(n-s.PaddingInner))*s.Align
+	return start + step*float64(i), true
+}
+
+// Ordinal maps each value of the domain onto the value at the same
+// position of the range, wrapping around when the range is shorter
+type Ordinal struct {
+	Domain []string
+	Range  []string
+	// Unknown is returned for values not in the domain
+	Unknown string
+}
+
+func (s Ordinal) Map(v string) string {
+	if len(s.Range) == 0 {
+		return s.Unknown
+	}
+	for i, d := range s.Domain {
+		if d == v {
+			return s.Range[i%len(s.Range)]
+		}
+	}
+	return s.Unknown
+}
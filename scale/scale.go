@@ -0,0 +1,251 @@
+// Package scale maps values from a data domain onto a visual range,
+// such as pixels, radii or proportions
+package scale
+
+import (
+	"math"
+
+	visual "github.com/osraige/visualisations"
+)
+
+// Continuous is a scale mapping a continuous domain onto a continuous
+// range
+type Continuous interface {
+	// Map takes a value from the domain into the range
+	Map(v float64) float64
+	// Invert takes a value from the range back into the domain
+	Invert(v float64) float64
+}
+
+// Extent returns the smallest and largest of `data` as a domain. an
+// empty slice gives a domain of 0 to 1
+func Extent(data []float64) [2]float64 {
+	if len(data) == 0 {
+		return [2]float64{0, 1}
+	}
+	ext := [2]float64{data[0], data[0]}
+	for _, v := range data[1:] {
+		ext[0] = math.Min(ext[0], v)
+		ext[1] = math.Max(ext[1], v)
+	}
+	return ext
+}
+
+// ExtentInt is Extent for integer data
+func ExtentInt(data []int) [2]float64 {
+	floats := make([]float64, len(data))
+	for i, v := range data {
+		floats[i] = float64(v)
+	}
+	return Extent(floats)
+}
+
+// Unit is the range 0 to 1, used by scales mapping onto a proportion
+var Unit = [2]float64{0, 1}
+
+// Linear maps the domain onto the range with a straight line
+type Linear struct {
+	Domain [2]float64
+	Range  [2]float64
+	// Clamp restricts mapped values to the range
+	Clamp bool
+}
+
+func (s Linear) Map(v float64) float64 {
+	if s.Domain[0] == s.Domain[1] {
+		return midpoint(s.Range)
+	}
+	if s.Clamp {
+		v = clamp(v, s.Domain)
+	}
+	return visual.ScaleRange(v, s.Domain[0], s.Domain[1], s.Range[0], s.Range[1])
+}
+
+func (s Linear) Invert(v float64) float64 {
+	if s.Range[0] == s.Range[1] {
+		return midpoint(s.Domain)
+	}
+	if s.Clamp {
+		v = clamp(v, s.Range)
+	}
+	return visual.ScaleRange(v, s.Range[0], s.Range[1], s.Domain[0], s.Domain[1])
+}
+
+// Nice extends the domain so it starts and ends on round values, using
+// a step size suitable for roughly `count` ticks
+func (s Linear) Nice(count int) Linear {
+	s.Domain = niceDomain(s.Domain, count)
+	return s
+}
+
+// Pow maps the domain onto the range by raising values to Exponent.
+// the sign of values is kept, so negative domains work as expected
+type Pow struct {
+	Domain   [2]float64
+	Range    [2]float64
+	Exponent float64
+	Clamp    bool
+}
+
+// Sqrt is a Pow scale with an exponent of 0.5, useful for mapping
+// values onto the radius of a circle so that its area is proportional
+func Sqrt(domain, rng [2]float64) Pow {
+	return Pow{Domain: domain, Range: rng, Exponent: 0.5}
+}
+
+func (s Pow) linear() Linear {
+	return Linear{
+		Domain: [2]float64{
+			signedPow(s.Domain[0], s.Exponent),
+			signedPow(s.Domain[1], s.Exponent),
+		},
+		Range: s.Range,
+	}
+}
+
+func (s Pow) Map(v float64) float64 {
+	if s.Clamp {
+		v = clamp(v, s.Domain)
+	}
+	return s.linear().Map(signedPow(v, s.Exponent))
+}
+
+func (s Pow) Invert(v float64) float64 {
+	if s.Clamp {
+		v = clamp(v, s.Range)
+	}
+	return signedPow(s.linear().Invert(v), 1/s.Exponent)
+}
+
+// Nice extends the domain so it starts and ends on round values
+func (s Pow) Nice(count int) Pow {
+	s.Domain = niceDomain(s.Domain, count)
+	return s
+}
+
+// Log maps the domain onto the range logarithmically. the domain must
+// not include or cross zero
+type Log struct {
+	Domain [2]float64
+	Range  [2]float64
+	// Base defaults to 10
+	Base  float64
+	Clamp bool
+}
+
+func (s Log) base() float64 {
+	if s.Base <= 0 || s.Base == 1 {
+		return 10
+	}
+	return s.Base
+}
+
+func (s Log) log(v float64) float64 {
+	if s.Domain[0] < 0 {
+		return -math.Log(-v) / math.Log(s.base())
+	}
+	return math.Log(v) / math.Log(s.base())
+}
+
+func (s Log) pow(v float64) float64 {
+	if s.Domain[0] < 0 {
+		return -math.Pow(s.base(), -v)
+	}
+	return math.Pow(s.base(), v)
+}
+
+func (s Log) linear() Linear {
+	return Linear{
+		Domain: [2]float64{s.log(s.Domain[0]), s.log(s.Domain[1])},
+		Range:  s.Range,
+	}
+}
+
+func (s Log) Map(v float64) float64 {
+	if s.Clamp {
+		v = clamp(v, s.Domain)
+	}
+	return s.linear().Map(s.log(v))
+}
+
+func (s Log) Invert(v float64) float64 {
+	if s.Clamp {
+		v = clamp(v, s.Range)
+	}
+	return s.pow(s.linear().Invert(v))
+}
+
+// Nice extends the domain to whole powers of the base
+func (s Log) Nice() Log {
+	lo, hi := 0, 1
+	if s.Domain[0] > s.Domain[1] {
+		lo, hi = 1, 0
+	}
+	if s.Domain[0] < 0 {
+		lo, hi = hi, lo
+	}
+	s.Domain[lo] = s.pow(math.Floor(s.log(s.Domain[lo])))
+	s.Domain[hi] = s.pow(math.Ceil(s.log(s.Domain[hi])))
+	return s
+}
+
+func signedPow(v, exp float64) float64 {
+	if v < 0 {
+		return -math.Pow(-v, exp)
+	}
+	return math.Pow(v, exp)
+}
+
+func midpoint(r [2]float64) float64 {
+	return (r[0] + r[1]) / 2
+}
+
+// clamp restricts `v` to `bounds`, which may be in either order
+func clamp(v float64, bounds [2]float64) float64 {
+	lo, hi := bounds[0], bounds[1]
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// niceStep returns a step of 1, 2 or 5 times a power of ten that
+// divides `span` into roughly `count` parts
+func niceStep(span float64, count int) float64 {
+	if count < 1 {
+		count = 1
+	}
+	raw := math.Abs(span) / float64(count)
+	if raw == 0 || math.IsInf(raw, 0) || math.IsNaN(raw) {
+		return 0
+	}
+	power := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch err := raw / power; {
+	case err >= math.Sqrt(50):
+		return 10 * power
+	case err >= math.Sqrt(10):
+		return 5 * power
+	case err >= math.Sqrt(2):
+		return 2 * power
+	default:
+		return power
+	}
+}
+
+func niceDomain(domain [2]float64, count int) [2]float64 {
+	lo, hi := 0, 1
+	if domain[0] > domain[1] {
+		lo, hi = 1, 0
+	}
+	// the step can change once the domain has been extended, so run
+	// it again to settle on a stable result
+	for i := 0; i < 2; i++ {
+		step := niceStep(domain[hi]-domain[lo], count)
+		if step == 0 {
+			return domain
+		}
+		domain[lo] = math.Floor(domain[lo]/step) * step
+		domain[hi] = math.Ceil(domain[hi]/step) * step
+	}
+	return domain
+}
@@ -0,0 +1,276 @@
+package scale
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestContinuous(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		scale Continuous
+		in    float64
+		want  float64
+		// lossy scales can't invert back to the input
+		lossy bool
+	}{
+		{
+			name:  "linear",
+			scale: Linear{Domain: [2]float64{0, 100}, Range: [2]float64{100, 250}},
+			in:    50,
+			want:  175,
+		}, {
+			name:  "linear-reversed",
+			scale: Linear{Domain: [2]float64{0, 10}, Range: [2]float64{1, 0}},
+			in:    2.5,
+			want:  0.75,
+		}, {
+			name:  "linear-unclamped",
+			scale: Linear{Domain: [2]float64{0, 10}, Range: Unit},
+			in:    20,
+			want:  2,
+		}, {
+			name:  "linear-clamped",
+			scale: Linear{Domain: [2]float64{0, 10}, Range: Unit, Clamp: true},
+			in:    20,
+			want:  1,
+			lossy: true,
+		}, {
+			name:  "linear-empty-domain",
+			scale: Linear{Domain: [2]float64{5, 5}, Range: [2]float64{0, 10}},
+			in:    5,
+			want:  5,
+			lossy: true,
+		}, {
+			name:  "sqrt",
+			scale: Sqrt([2]float64{0, 100}, [2]float64{0, 10}),
+			in:    25,
+			want:  5,
+		}, {
+			name:  "pow-negative",
+			scale: Pow{Domain: [2]float64{-4, 4}, Range: [2]float64{-2, 2}, Exponent: 0.5},
+			in:    -1,
+			want:  -1,
+		}, {
+			name:  "log",
+			scale: Log{Domain: [2]float64{1, 1000}, Range: [2]float64{0, 3}},
+			in:    100,
+			want:  2,
+		}, {
+			name:  "log-base-2",
+			scale: Log{Domain: [2]float64{1, 16}, Range: Unit, Base: 2},
+			in:    4,
+			want:  0.5,
+		}, {
+			name:  "log-negative",
+			scale: Log{Domain: [2]float64{-1000, -1}, Range: [2]float64{0, 3}},
+			in:    -10,
+			want:  2,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			got := testcase.scale.Map(testcase.in)
+			if !near(got, testcase.want) {
+				t.Fatalf("Map(%v): got %v, want %v", testcase.in, got, testcase.want)
+			}
+			if testcase.lossy {
+				return
+			}
+			if back := testcase.scale.Invert(got); !near(back, testcase.in) {
+				t.Errorf("Invert(%v): got %v, want %v", got, back, testcase.in)
+			}
+		})
+	}
+}
+
+func TestNice(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		got  [2]float64
+		want [2]float64
+	}{
+		{
+			name: "linear",
+			got:  Linear{Domain: [2]float64{0.3, 97.2}}.Nice(10).Domain,
+			want: [2]float64{0, 100},
+		}, {
+			name: "linear-reversed",
+			got:  Linear{Domain: [2]float64{12.5, -3.1}}.Nice(5).Domain,
+			want: [2]float64{15, -5},
+		}, {
+			name: "linear-small",
+			got:  Linear{Domain: [2]float64{0.0012, 0.0087}}.Nice(5).Domain,
+			want: [2]float64{0, 0.01},
+		}, {
+			name: "log",
+			got:  Log{Domain: [2]float64{3, 420}}.Nice().Domain,
+			want: [2]float64{1, 1000},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if !near(testcase.got[0], testcase.want[0]) ||
+				!near(testcase.got[1], testcase.want[1]) {
+				t.Errorf("got %v, want %v", testcase.got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestExtent(t *testing.T) {
+	if got := ExtentInt([]int{5, -2, 9, 3}); got != [2]float64{-2, 9} {
+		t.Errorf("ExtentInt: got %v", got)
+	}
+	if got := Extent(nil); got != Unit {
+		t.Errorf("Extent(nil): got %v", got)
+	}
+}
+
+func TestTime(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := Time{
+		Domain: [2]time.Time{start, start.Add(24 * time.Hour)},
+		Range:  [2]float64{0, 240},
+	}
+	if got := s.Map(start.Add(6 * time.Hour)); !near(got, 60) {
+		t.Errorf("Map: got %v", got)
+	}
+	if got := s.Invert(120); !got.Equal(start.Add(12 * time.Hour)) {
+		t.Errorf("Invert: got %v", got)
+	}
+	// beyond the years UnixNano can hold
+	ancient := time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)
+	far := Time{Domain: [2]time.Time{ancient, ancient.AddDate(2000, 0, 0)}, Range: [2]float64{0, 100}}
+	if got := far.Map(ancient.AddDate(1000, 0, 0)); got < 49.9 || got > 50.1 {
+		t.Errorf("Map of year 2000: got %v", got)
+	}
+	if got := TimeExtent(nil); Unix(got[0]) != 0 || Unix(got[1]) != 1 {
+		t.Errorf("TimeExtent(nil): got %v", got)
+	}
+}
+
+func TestBand(t *testing.T) {
+	s := Band{
+		Domain:       []string{"a", "b", "c"},
+		Range:        [2]float64{0, 100},
+		PaddingInner: 0.2,
+		PaddingOuter: 0.1,
+		Align:        0.5,
+	}
+	step := 100 / (3 - 0.2 + 0.2)
+	if !near(s.Step(), step) || !near(s.Bandwidth(), step*0.8) {
+		t.Errorf("got step %v bandwidth %v", s.Step(), s.Bandwidth())
+	}
+	if got, ok := s.Map("b"); !ok || !near(got, step*0.1+step) {
+		t.Errorf("Map(b): got %v, %v", got, ok)
+	}
+	if _, ok := s.Map("z"); ok {
+		t.Errorf("Map(z): expected to not be in the domain")
+	}
+	o := Ordinal{Domain: []string{"a", "b", "c"}, Range: []string{"red", "blue"}, Unknown: "grey"}
+	if got := o.Map("c") + o.Map("z"); got != "redgrey" {
+		t.Errorf("Ordinal: got %s", got)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		got  []float64
+		want []float64
+	}{
+		{
+			name: "ones",
+			got:  Ticks(0, 5, 5),
+			want: []float64{0, 1, 2, 3, 4, 5},
+		}, {
+			name: "twos",
+			got:  Ticks(-3, 9, 5),
+			want: []float64{-2, 0, 2, 4, 6, 8},
+		}, {
+			name: "fives-small",
+			got:  Ticks(0, 0.2, 4),
+			want: []float64{0, 0.05, 0.1, 0.15, 0.2},
+		}, {
+			name: "reversed",
+			got:  Linear{Domain: [2]float64{100, 0}}.Ticks(2),
+			want: []float64{100, 50, 0},
+		}, {
+			name: "log",
+			got:  Log{Domain: [2]float64{1, 10000}}.Ticks(3),
+			want: []float64{1, 10, 100, 1000, 10000},
+		}, {
+			name: "log-multiples",
+			got:  Log{Domain: [2]float64{1, 50}}.Ticks(10),
+			want: []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 20, 30, 40, 50},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if len(testcase.got) != len(testcase.want) {
+				t.Fatalf("got %v, want %v", testcase.got, testcase.want)
+			}
+			for i := range testcase.got {
+				if !near(testcase.got[i], testcase.want[i]) {
+					t.Fatalf("got %v, want %v", testcase.got, testcase.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeTicks(t *testing.T) {
+	start := time.Date(2020, 1, 30, 9, 20, 0, 0, time.UTC)
+	for _, testcase := range []struct {
+		name   string
+		end    time.Time
+		count  int
+		layout string
+		want   []string
+	}{
+		{
+			name:   "quarter-hours",
+			end:    start.Add(time.Hour),
+			count:  4,
+			layout: "15:04",
+			want:   []string{"09:30", "09:45", "10:00", "10:15"},
+		}, {
+			name:   "days",
+			end:    start.Add(3 * day),
+			count:  3,
+			layout: "Jan 2 15:04",
+			want:   []string{"Jan 31 00:00", "Feb 1 00:00", "Feb 2 00:00"},
+		}, {
+			name:   "months",
+			end:    start.AddDate(0, 5, 0),
+			count:  5,
+			layout: "Jan 2",
+			want:   []string{"Feb 1", "Mar 1", "Apr 1", "May 1", "Jun 1"},
+		}, {
+			name:   "years",
+			end:    start.AddDate(30, 0, 0),
+			count:  3,
+			layout: "2006",
+			want:   []string{"2030", "2040", "2050"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			s := Time{Domain: [2]time.Time{start, testcase.end}}
+			got := []string{}
+			for _, tick := range s.Ticks(testcase.count) {
+				got = append(got, tick.Format(testcase.layout))
+			}
+			if strings.Join(got, ",") != strings.Join(testcase.want, ",") {
+				t.Errorf("got %v, want %v", got, testcase.want)
+			}
+		})
+	}
+	nice := Time{Domain: [2]time.Time{start, start.Add(time.Hour)}}.Nice(4)
+	if got := nice.Domain[0].Format("15:04") + "-" + nice.Domain[1].Format("15:04"); got != "09:15-10:30" {
+		t.Errorf("Nice: got %s", got)
+	}
+}
@@ -0,0 +1,244 @@
+package scale
+
+import (
+	"math"
+	"time"
+)
+
+// Ticker is a continuous scale that can suggest tick values
+type Ticker interface {
+	Continuous
+	Ticks(count int) []float64
+}
+
+// Ticks returns round values between `lo` and `hi`, spaced 1, 2 or 5
+// times a power of ten apart so there are roughly `count` of them
+func Ticks(lo, hi float64, count int) []float64 {
+	reverse := lo > hi
+	if reverse {
+		lo, hi = hi, lo
+	}
+	step := niceStep(hi-lo, count)
+	if step == 0 {
+		if lo == hi && !math.IsNaN(lo) {
+			return []float64{lo}
+		}
+		return nil
+	}
+	// work in whole steps to avoid accumulating rounding errors
+	first := math.Ceil(lo / step)
+	last := math.Floor(hi / step)
+	ticks := make([]float64, 0, int(last-first)+1)
+	for i := first; i <= last; i++ {
+		ticks = append(ticks, cleanFloat(i*step))
+	}
+	if reverse {
+		for i, j := 0, len(ticks)-1; i < j; i, j = i+1, j-1 {
+			ticks[i], ticks[j] = ticks[j], ticks[i]
+		}
+	}
+	return ticks
+}
+
+// TickStep is the distance between the values Ticks would return
+func TickStep(lo, hi float64, count int) float64 {
+	return niceStep(hi-lo, count)
+}
+
+// cleanFloat removes the noise left by multiplying a step, so that
+// 3*0.1 is 0.3 rather than 0.30000000000000004
+func cleanFloat(v float64) float64 {
+	return math.Round(v*1e12) / 1e12
+}
+
+func (s Linear) Ticks(count int) []float64 {
+	return Ticks(s.Domain[0], s.Domain[1], count)
+}
+
+func (s Pow) Ticks(count int) []float64 {
+	return Ticks(s.Domain[0], s.Domain[1], count)
+}
+
+// Ticks returns the powers of the base within the domain. when that
+// gives fewer than `count` ticks the integer multiples of each power
+// are included too
+func (s Log) Ticks(count int) []float64 {
+	lo, hi := s.Domain[0], s.Domain[1]
+	reverse := lo > hi
+	if reverse {
+		lo, hi = hi, lo
+	}
+	sign := 1.0
+	if lo < 0 {
+		sign, lo, hi = -1, -hi, -lo
+	}
+	if lo <= 0 {
+		return nil
+	}
+	base := s.base()
+	p0 := math.Floor(math.Log(lo) / math.Log(base))
+	p1 := math.Ceil(math.Log(hi) / math.Log(base))
+	multiples := p1-p0 < float64(count) && base == math.Trunc(base)
+	ticks := []float64{}
+	for p := p0; p <= p1; p++ {
+		power := math.Pow(base, p)
+		for k := 1.0; k < base; k++ {
+			if k > 1 && !multiples {
+				break
+			}
+			v := cleanFloat(power * k)
+			if v >= lo && v <= hi {
+				ticks = append(ticks, sign*v)
+			}
+		}
+	}
+	if reverse != (sign < 0) {
+		for i, j := 0, len(ticks)-1; i < j; i, j = i+1, j-1 {
+			ticks[i], ticks[j] = ticks[j], ticks[i]
+		}
+	}
+	return ticks
+}
+
+type timeUnit int
+
+const (
+	unitDuration timeUnit = iota
+	unitDay
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+type timeInterval struct {
+	unit timeUnit
+	// step is either a fixed duration or a count of the unit
+	duration time.Duration
+	count    int
+	// approx is used to pick the interval closest to a target step
+	approx time.Duration
+}
+
+func fixedInterval(d time.Duration) timeInterval {
+	return timeInterval{unit: unitDuration, duration: d, approx: d}
+}
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+	year  = 365 * day
+)
+
+// timeIntervals are the natural boundaries ticks are placed on
+var timeIntervals = []timeInterval{
+	fixedInterval(time.Second),
+	fixedInterval(5 * time.Second),
+	fixedInterval(15 * time.Second),
+	fixedInterval(30 * time.Second),
+	fixedInterval(time.Minute),
+	fixedInterval(5 * time.Minute),
+	fixedInterval(15 * time.Minute),
+	fixedInterval(30 * time.Minute),
+	fixedInterval(time.Hour),
+	fixedInterval(3 * time.Hour),
+	fixedInterval(6 * time.Hour),
+	fixedInterval(12 * time.Hour),
+	{unit: unitDay, count: 1, approx: day},
+	{unit: unitDay, count: 2, approx: 2 * day},
+	{unit: unitWeek, count: 1, approx: week},
+	{unit: unitMonth, count: 1, approx: month},
+	{unit: unitMonth, count: 3, approx: 3 * month},
+	{unit: unitYear, count: 1, approx: year},
+}
+
+func chooseInterval(span time.Duration, count int) timeInterval {
+	if count < 1 {
+		count = 1
+	}
+	target := span / time.Duration(count)
+	for i, interval := range timeIntervals {
+		if interval.approx < target {
+			continue
+		}
+		// pick whichever neighbour is closer to the target
+		if i > 0 && target-timeIntervals[i-1].approx < interval.approx-target {
+			return timeIntervals[i-1]
+		}
+		return interval
+	}
+	// beyond a year use a round number of years
+	years := int(niceStep(float64(target)/float64(year), 1))
+	if years < 1 {
+		years = 1
+	}
+	return timeInterval{unit: unitYear, count: years, approx: time.Duration(years) * year}
+}
+
+// floor rounds `t` down to the start of the interval containing it
+func (i timeInterval) floor(t time.Time) time.Time {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch i.unit {
+	case unitDuration:
+		midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		return midnight.Add(t.Sub(midnight) / i.duration * i.duration)
+	case unitDay:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	case unitWeek:
+		// weeks start on monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case unitMonth:
+		m = time.Month((int(m)-1)/i.count*i.count + 1)
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y/i.count*i.count, 1, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+func (i timeInterval) next(t time.Time) time.Time {
+	switch i.unit {
+	case unitDuration:
+		return t.Add(i.duration)
+	case unitDay:
+		return t.AddDate(0, 0, i.count)
+	case unitWeek:
+		return t.AddDate(0, 0, 7*i.count)
+	case unitMonth:
+		return t.AddDate(0, i.count, 0)
+	default:
+		return t.AddDate(i.count, 0, 0)
+	}
+}
+
+// Ticks returns times within the domain on natural boundaries, such as
+// the hour, midnight or the first of the month, roughly `count` of them
+func (s Time) Ticks(count int) []time.Time {
+	lo, hi := s.Domain[0], s.Domain[1]
+	if lo.After(hi) {
+		lo, hi = hi, lo
+	}
+	interval := chooseInterval(hi.Sub(lo), count)
+	ticks := []time.Time{}
+	for t := interval.floor(lo); !t.After(hi); t = interval.next(t) {
+		if !t.Before(lo) {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks
+}
+
+// Nice extends the domain to the natural boundaries either side of it
+func (s Time) Nice(count int) Time {
+	lo, hi := 0, 1
+	if s.Domain[0].After(s.Domain[1]) {
+		lo, hi = 1, 0
+	}
+	interval := chooseInterval(s.Domain[hi].Sub(s.Domain[lo]), count)
+	s.Domain[lo] = interval.floor(s.Domain[lo])
+	if end := interval.floor(s.Domain[hi]); !end.Equal(s.Domain[hi]) {
+		s.Domain[hi] = interval.next(end)
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+package scale
+
+import (
+	"math"
+	"time"
+)
+
+// Time maps a span of time onto the range linearly
+type Time struct {
+	Domain [2]time.Time
+	Range  [2]float64
+	Clamp  bool
+}
+
+// TimeExtent returns the earliest and latest of `data` as a domain. as
+// with Extent, an empty slice gives a domain of 0 to 1 unix seconds
+func TimeExtent(data []time.Time) [2]time.Time {
+	if len(data) == 0 {
+		return [2]time.Time{time.Unix(0, 0).UTC(), time.Unix(1, 0).UTC()}
+	}
+	ext := [2]time.Time{data[0], data[0]}
+	for _, t := range data[1:] {
+		if t.Before(ext[0]) {
+			ext[0] = t
+		}
+		if t.After(ext[1]) {
+			ext[1] = t
+		}
+	}
+	return ext
+}
+
+// Seconds is the equivalent linear scale over unix seconds, for use
+// where a Continuous scale is needed
+func (s Time) Seconds() Linear {
+	return Linear{
+		Domain: [2]float64{Unix(s.Domain[0]), Unix(s.Domain[1])},
+		Range:  s.Range,
+		Clamp:  s.Clamp,
+	}
+}
+
+// Map takes a time from the domain into the range
+func (s Time) Map(t time.Time) float64 {
+	return s.Seconds().Map(Unix(t))
+}
+
+// Invert takes a value from the range back to a time, in the location
+// of the start of the domain
+func (s Time) Invert(v float64) time.Time {
+	return FromUnix(s.Seconds().Invert(v)).In(s.Domain[0].Location())
+}
+
+// Unix converts `t` into the unix seconds used by Seconds. unlike
+// UnixNano it doesn't overflow for times outside 1678 to 2262
+func Unix(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/float64(time.Second)
+}
+
+// FromUnix converts unix seconds back into a time
+func FromUnix(secs float64) time.Time {
+	whole := math.Floor(secs)
+	return time.Unix(int64(whole), int64((secs-whole)*float64(time.Second)))
+}
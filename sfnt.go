@@ -0,0 +1,394 @@
+package visualisations
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// this file reads and writes the tables of TrueType and OpenType font
+// files, enough to subset TrueType outlines
+
+var errFontTruncated = errors.New("visualisations: font: file is truncated")
+
+// subsetTables are the tables kept in a subset. layout tables such as
+// GSUB are left out as they refer to glyphs that may have been dropped,
+// so ligatures and kerning are lost
+var subsetTables = map[string]bool{
+	"cmap": true, "head": true, "hhea": true, "hmtx": true, "maxp": true,
+	"name": true, "OS/2": true, "post": true, "glyf": true, "loca": true,
+	"cvt ": true, "fpgm": true, "prep": true, "gasp": true,
+}
+
+// parseTables returns the tables of an sfnt font file by tag
+func parseTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errFontTruncated
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*n {
+		return nil, errFontTruncated
+	}
+	tables := map[string][]byte{}
+	for i := 0; i < n; i++ {
+		record := data[12+16*i:]
+		offset := binary.BigEndian.Uint32(record[8:])
+		length := binary.BigEndian.Uint32(record[12:])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, errFontTruncated
+		}
+		tables[string(record[:4])] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// subsetTrueType returns a copy of the TrueType font `data` with the
+// outlines of every glyph not needed to draw `runes` removed. glyphs
+// keep their ids, so the metrics and hinting tables stay valid
+func subsetTrueType(data []byte, runes []rune) ([]byte, error) {
+	tables, err := parseTables(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"cmap", "head", "hhea", "hmtx", "maxp", "loca", "glyf"} {
+		if tables[tag] == nil {
+			return nil, errors.New("visualisations: font: missing " + tag + " table")
+		}
+	}
+	head, maxp, loca, glyf := tables["head"], tables["maxp"], tables["loca"], tables["glyf"]
+	if len(head) < 54 || len(maxp) < 6 {
+		return nil, errFontTruncated
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	long := binary.BigEndian.Uint16(head[50:]) == 1
+	offsets := make([]uint32, numGlyphs+1)
+	for i := range offsets {
+		if long {
+			if len(loca) < 4*i+4 {
+				return nil, errFontTruncated
+			}
+			offsets[i] = binary.BigEndian.Uint32(loca[4*i:])
+		} else {
+			if len(loca) < 2*i+2 {
+				return nil, errFontTruncated
+			}
+			offsets[i] = 2 * uint32(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+		if offsets[i] > uint32(len(glyf)) || (i > 0 && offsets[i] < offsets[i-1]) {
+			return nil, errors.New("visualisations: font: invalid loca table")
+		}
+	}
+	glyph := func(id int) []byte {
+		return glyf[offsets[id]:offsets[id+1]]
+	}
+
+	// the glyphs for the runes, .notdef and every component of the
+	// composite glyphs among them
+	mapping := map[rune]uint16{}
+	keep := map[int]bool{0: true}
+	queue := []int{0}
+	for _, r := range runes {
+		id, ok := lookupGlyph(tables["cmap"], r)
+		if !ok || int(id) >= numGlyphs {
+			continue
+		}
+		mapping[r] = id
+		if !keep[int(id)] {
+			keep[int(id)] = true
+			queue = append(queue, int(id))
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, c := range components(glyph(id)) {
+			if int(c) < numGlyphs && !keep[int(c)] {
+				keep[int(c)] = true
+				queue = append(queue, int(c))
+			}
+		}
+	}
+
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for id := 0; id < numGlyphs; id++ {
+		binary.BigEndian.PutUint32(newLoca[4*id:], uint32(len(newGlyf)))
+		if keep[id] {
+			newGlyf = append(newGlyf, glyph(id)...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(len(newGlyf)))
+
+	out := map[string][]byte{}
+	for tag, table := range tables {
+		if subsetTables[tag] {
+			out[tag] = table
+		}
+	}
+	out["glyf"] = newGlyf
+	out["loca"] = newLoca
+	out["cmap"] = buildCmap(mapping)
+	out["head"] = append([]byte(nil), head...)
+	// loca is always written with long offsets
+	binary.BigEndian.PutUint16(out["head"][50:], 1)
+	if post := tables["post"]; len(post) >= 32 {
+		// version 3 has no glyph names
+		out["post"] = append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(out["post"], 0x00030000)
+	}
+	return writeTables(binary.BigEndian.Uint32(data), out), nil
+}
+
+// lookupGlyph finds the glyph of `r` in a cmap table, from its unicode
+// subtable of format 4 or 12
+func lookupGlyph(cmap []byte, r rune) (uint16, bool) {
+	if len(cmap) < 4 {
+		return 0, false
+	}
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	found := false
+	var id uint16
+	for i := 0; i < n && 4+8*i+8 <= len(cmap); i++ {
+		record := cmap[4+8*i:]
+		platform, encoding := binary.BigEndian.Uint16(record), binary.BigEndian.Uint16(record[2:])
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		offset := binary.BigEndian.Uint32(record[4:])
+		if uint64(offset)+2 > uint64(len(cmap)) {
+			continue
+		}
+		sub := cmap[offset:]
+		switch binary.BigEndian.Uint16(sub) {
+		case 4:
+			id, found = lookupFormat4(sub, r)
+		case 12:
+			id, found = lookupFormat12(sub, r)
+		}
+		if found {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func lookupFormat4(sub []byte, r rune) (uint16, bool) {
+	if r > 0xffff || len(sub) < 14 {
+		return 0, false
+	}
+	segments := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	if len(sub) < 16+8*segments {
+		return 0, false
+	}
+	c := uint16(r)
+	for i := 0; i < segments; i++ {
+		end := binary.BigEndian.Uint16(sub[14+2*i:])
+		if end < c {
+			continue
+		}
+		start := binary.BigEndian.Uint16(sub[16+2*segments+2*i:])
+		if start > c {
+			return 0, false
+		}
+		delta := binary.BigEndian.Uint16(sub[16+4*segments+2*i:])
+		rangeAt := 16 + 6*segments + 2*i
+		rangeOffset := int(binary.BigEndian.Uint16(sub[rangeAt:]))
+		if rangeOffset == 0 {
+			return c + delta, c+delta != 0
+		}
+		at := rangeAt + rangeOffset + 2*int(c-start)
+		if at+2 > len(sub) {
+			return 0, false
+		}
+		id := binary.BigEndian.Uint16(sub[at:])
+		if id == 0 {
+			return 0, false
+		}
+		return id + delta, true
+	}
+	return 0, false
+}
+
+func lookupFormat12(sub []byte, r rune) (uint16, bool) {
+	if len(sub) < 16 {
+		return 0, false
+	}
+	n := int(binary.BigEndian.Uint32(sub[12:]))
+	for i := 0; i < n && 16+12*i+12 <= len(sub); i++ {
+		group := sub[16+12*i:]
+		start, end := binary.BigEndian.Uint32(group), binary.BigEndian.Uint32(group[4:])
+		if uint32(r) >= start && uint32(r) <= end {
+			id := binary.BigEndian.Uint32(group[8:]) + uint32(r) - start
+			return uint16(id), id != 0 && id <= 0xffff
+		}
+	}
+	return 0, false
+}
+
+// components returns the glyphs a composite glyph is built from
+func components(glyph []byte) []uint16 {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords  = 0x0001
+		haveScale     = 0x0008
+		moreComponent = 0x0020
+		haveXYScale   = 0x0040
+		haveTwoByTwo  = 0x0080
+	)
+	var ids []uint16
+	for at := 10; at+4 <= len(glyph); {
+		flags := binary.BigEndian.Uint16(glyph[at:])
+		ids = append(ids, binary.BigEndian.Uint16(glyph[at+2:]))
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			at += 2
+		case flags&haveXYScale != 0:
+			at += 4
+		case flags&haveTwoByTwo != 0:
+			at += 8
+		}
+		if flags&moreComponent == 0 {
+			break
+		}
+	}
+	return ids
+}
+
+// buildCmap writes a cmap table mapping runes to glyphs. runes outside
+// the basic multilingual plane need a format 12 subtable as well
+func buildCmap(mapping map[rune]uint16) []byte {
+	runes := make([]rune, 0, len(mapping))
+	for r := range mapping {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	// format 4 with a segment for each rune, ending with the 0xffff
+	// segment it requires
+	var bmp []rune
+	for _, r := range runes {
+		if r < 0xffff {
+			bmp = append(bmp, r)
+		}
+	}
+	segments := len(bmp) + 1
+	format4 := make([]byte, 16+8*segments)
+	put16 := func(b []byte, at int, v uint16) { binary.BigEndian.PutUint16(b[at:], v) }
+	put16(format4, 0, 4)
+	put16(format4, 2, uint16(len(format4)))
+	put16(format4, 6, uint16(2*segments))
+	searchRange, selector := 2, 0
+	for searchRange*2 <= 2*segments {
+		searchRange *= 2
+		selector++
+	}
+	put16(format4, 8, uint16(searchRange))
+	put16(format4, 10, uint16(selector))
+	put16(format4, 12, uint16(2*segments-searchRange))
+	for i := 0; i < segments; i++ {
+		c, id := uint16(0xffff), uint16(0)
+		if i < len(bmp) {
+			c, id = uint16(bmp[i]), mapping[bmp[i]]
+		}
+		put16(format4, 14+2*i, c)
+		put16(format4, 16+2*segments+2*i, c)
+		put16(format4, 16+4*segments+2*i, id-c)
+	}
+
+	var format12 []byte
+	if len(bmp) < len(runes) {
+		format12 = make([]byte, 16+12*len(runes))
+		binary.BigEndian.PutUint16(format12, 12)
+		binary.BigEndian.PutUint32(format12[4:], uint32(len(format12)))
+		binary.BigEndian.PutUint32(format12[12:], uint32(len(runes)))
+		for i, r := range runes {
+			group := format12[16+12*i:]
+			binary.BigEndian.PutUint32(group, uint32(r))
+			binary.BigEndian.PutUint32(group[4:], uint32(r))
+			binary.BigEndian.PutUint32(group[8:], uint32(mapping[r]))
+		}
+	}
+
+	n := 1
+	if format12 != nil {
+		n = 2
+	}
+	cmap := make([]byte, 4+8*n)
+	put16(cmap, 2, uint16(n))
+	put16(cmap, 4, 3)
+	put16(cmap, 6, 1)
+	binary.BigEndian.PutUint32(cmap[8:], uint32(len(cmap)))
+	if format12 != nil {
+		put16(cmap, 12, 3)
+		put16(cmap, 14, 10)
+		binary.BigEndian.PutUint32(cmap[16:], uint32(len(cmap)+len(format4)))
+	}
+	cmap = append(cmap, format4...)
+	return append(cmap, format12...)
+}
+
+// writeTables writes an sfnt font file with `tables`, setting the
+// checksums it requires
+func writeTables(version uint32, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	n := len(tags)
+	searchRange, selector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		selector++
+	}
+	out := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(out, version)
+	binary.BigEndian.PutUint16(out[4:], uint16(n))
+	binary.BigEndian.PutUint16(out[6:], uint16(16*searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(selector))
+	binary.BigEndian.PutUint16(out[10:], uint16(16*(n-searchRange)))
+	headAt := -1
+	for i, tag := range tags {
+		table := tables[tag]
+		if tag == "head" {
+			headAt = len(out)
+			// the adjustment is zero while the checksums are summed
+			table = append([]byte(nil), table...)
+			binary.BigEndian.PutUint32(table[8:], 0)
+		}
+		record := out[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], checksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	if headAt >= 0 {
+		binary.BigEndian.PutUint32(out[headAt+8:], 0xb1b0afba-checksum(out))
+	}
+	return out
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
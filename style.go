@@ -0,0 +1,159 @@
+package visualisations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Style holds the presentation attributes of an svg element. empty
+// strings and nil pointers are treated as unset and are left out when
+// the style is rendered, so a zero Style renders as an empty string
+type Style struct {
+	Fill          string
+	FillOpacity   *float64
+	Stroke        string
+	StrokeWidth   *float64
+	StrokeOpacity *float64
+	StrokeLineCap CapStyle
+	// FillPaint and StrokePaint replace Fill and Stroke on canvases that
+	// can draw gradients and patterns
+	FillPaint   *Paint
+	StrokePaint *Paint
+	// Filter applies an effect such as a drop shadow on canvases that
+	// support it
+	Filter *Filter
+
+	FontFamily       string
+	FontSize         *int
+	FontWeight       string
+	TextAnchor       string
+	DominantBaseline string
+
+	// Class is the semantic class of the element, such as gauge-fill. it
+	// is not part of the css and is only written by canvases that style
+	// elements with classes
+	Class string
+}
+
+// Float returns a pointer to `f`, for use with the optional numeric
+// fields of Style
+func Float(f float64) *float64 {
+	return &f
+}
+
+// Int returns a pointer to `i`, for use with the optional numeric
+// fields of Style
+func Int(i int) *int {
+	return &i
+}
+
+// Merge returns a copy of `s` with any unset fields filled in from
+// `other`. fields already set on `s` are kept
+func (s Style) Merge(other Style) Style {
+	return other.Override(s)
+}
+
+// Override returns a copy of `s` with every field that is set on
+// `other` replacing the value in `s`
+func (s Style) Override(other Style) Style {
+	if other.Fill != "" {
+		s.Fill = other.Fill
+	}
+	if other.FillOpacity != nil {
+		s.FillOpacity = other.FillOpacity
+	}
+	if other.Stroke != "" {
+		s.Stroke = other.Stroke
+	}
+	if other.StrokeWidth != nil {
+		s.StrokeWidth = other.StrokeWidth
+	}
+	if other.StrokeOpacity != nil {
+		s.StrokeOpacity = other.StrokeOpacity
+	}
+	if other.StrokeLineCap != "" {
+		s.StrokeLineCap = other.StrokeLineCap
+	}
+	if other.FillPaint != nil {
+		s.FillPaint = other.FillPaint
+	}
+	if other.StrokePaint != nil {
+		s.StrokePaint = other.StrokePaint
+	}
+	if other.Filter != nil {
+		s.Filter = other.Filter
+	}
+	if other.FontFamily != "" {
+		s.FontFamily = other.FontFamily
+	}
+	if other.FontSize != nil {
+		s.FontSize = other.FontSize
+	}
+	if other.FontWeight != "" {
+		s.FontWeight = other.FontWeight
+	}
+	if other.TextAnchor != "" {
+		s.TextAnchor = other.TextAnchor
+	}
+	if other.DominantBaseline != "" {
+		s.DominantBaseline = other.DominantBaseline
+	}
+	if other.Class != "" {
+		s.Class = other.Class
+	}
+	return s
+}
+
+// IsZero reports whether no fields of `s` are set
+func (s Style) IsZero() bool {
+	return s.String() == "" && s.Class == "" && s.FillPaint == nil && s.StrokePaint == nil &&
+		s.Filter == nil
+}
+
+// Declarations returns the css declarations of `s` as "property:value"
+// pairs, always in the same order
+func (s Style) Declarations() []string {
+	decls := []string{}
+	add := func(property, value string) {
+		decls = append(decls, property+":"+value)
+	}
+	if s.Fill != "" {
+		add("fill", s.Fill)
+	}
+	if s.FillOpacity != nil {
+		add("fill-opacity", fmt.Sprintf("%f", *s.FillOpacity))
+	}
+	if s.Stroke != "" {
+		add("stroke", s.Stroke)
+	}
+	if s.StrokeWidth != nil {
+		add("stroke-width", fmt.Sprintf("%.1f", *s.StrokeWidth))
+	}
+	if s.StrokeOpacity != nil {
+		add("stroke-opacity", fmt.Sprintf("%f", *s.StrokeOpacity))
+	}
+	if s.StrokeLineCap != "" {
+		add("stroke-linecap", string(s.StrokeLineCap))
+	}
+	if s.FontFamily != "" {
+		add("font-family", s.FontFamily)
+	}
+	if s.FontSize != nil {
+		add("font-size", fmt.Sprintf("%vpx", *s.FontSize))
+	}
+	if s.FontWeight != "" {
+		add("font-weight", s.FontWeight)
+	}
+	if s.TextAnchor != "" {
+		add("text-anchor", s.TextAnchor)
+	}
+	if s.DominantBaseline != "" {
+		add("dominant-baseline", s.DominantBaseline)
+	}
+	return decls
+}
+
+// String renders `s` as the value of an inline style attribute
+func (s Style) String() string {
+	return strings.Join(s.Declarations(), ";")
+}
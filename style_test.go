@@ -0,0 +1,84 @@
+package visualisations
+
+import "testing"
+
+func TestStyleString(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		style Style
+		want  string
+	}{
+		{
+			name:  "empty",
+			style: Style{},
+			want:  "",
+		}, {
+			name: "stroke",
+			style: Style{
+				StrokeLineCap: CapStyleRound,
+				StrokeWidth:   Float(3),
+				Stroke:        "red",
+				Fill:          "none",
+			},
+			want: "fill:none;stroke:red;stroke-width:3.0;stroke-linecap:round",
+		}, {
+			name: "text",
+			style: Style{
+				DominantBaseline: "central",
+				FontSize:         Int(0),
+				FontFamily:       "monospace",
+				FillOpacity:      Float(0.5),
+			},
+			want: "fill-opacity:0.500000;font-family:monospace;font-size:0px;dominant-baseline:central",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if got := testcase.style.String(); got != testcase.want {
+				t.Errorf("got %q, want %q", got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestStyleMergeOverride(t *testing.T) {
+	base := Style{Fill: "none", Stroke: "black", StrokeWidth: Float(2)}
+	other := Style{Stroke: "red", StrokeOpacity: Float(0)}
+
+	merged := base.Merge(other).String()
+	if want := "fill:none;stroke:black;stroke-width:2.0;stroke-opacity:0.000000"; merged != want {
+		t.Errorf("Merge: got %q, want %q", merged, want)
+	}
+	overridden := base.Override(other).String()
+	if want := "fill:none;stroke:red;stroke-width:2.0;stroke-opacity:0.000000"; overridden != want {
+		t.Errorf("Override: got %q, want %q", overridden, want)
+	}
+	if base.Stroke != "black" {
+		t.Errorf("Override modified the receiver: %q", base.Stroke)
+	}
+}
+
+func TestParseStyles(t *testing.T) {
+	// empty declarations are kept, as they were before Style existed
+	got := ParseStyles(ParseFill("red"), "", ParseStroke("blue"))
+	if want := "fill:red;;stroke:blue"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseHelpers(t *testing.T) {
+	// the helpers keep their original output, even for empty values
+	for _, testcase := range []struct {
+		got, want string
+	}{
+		{ParseFill(""), "fill:"},
+		{ParseFillOpacity(0.5), "fill-opacity:0.500000"},
+		{ParseStrokeWidth(2), "stroke-width:2.0"},
+		{ParseStrokeLineCap(CapStyleRound), "stroke-linecap:round"},
+		{ParseFontFamily(""), "font-family:"},
+		{ParseFontSize(0), "font-size:0px"},
+	} {
+		if testcase.got != testcase.want {
+			t.Errorf("got %q, want %q", testcase.got, testcase.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package visualisations
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// classMarker matches the markers left in place of class attributes
+// until the whole document has been seen. xml can't hold NUL, so they
+// never clash with real content
+var classMarker = regexp.MustCompile("\x00([0-9]+)\x00")
+
+var cssEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// stylesheet collects the styles of elements by their semantic class.
+// elements of one class with different styles, such as the entries of
+// a timeline, are told apart by numbered variants of the class
+type stylesheet struct {
+	// classes in the order they were first used
+	classes []string
+	// variants are the distinct styles seen for each class
+	variants map[string][]Style
+	// uses are the class and variant of each marker
+	uses  []classUse
+	index map[string]int
+}
+
+type classUse struct {
+	class   string
+	variant int
+}
+
+func newStylesheet() *stylesheet {
+	return &stylesheet{variants: map[string][]Style{}, index: map[string]int{}}
+}
+
+// marker records an element with `style` and returns the marker to
+// write in place of its class
+func (s *stylesheet) marker(style Style) string {
+	key := style.Class + "\x00" + style.String()
+	i, ok := s.index[key]
+	if !ok {
+		variants, seen := s.variants[style.Class]
+		if !seen {
+			s.classes = append(s.classes, style.Class)
+		}
+		s.variants[style.Class] = append(variants, style)
+		i = len(s.uses)
+		s.index[key] = i
+		s.uses = append(s.uses, classUse{style.Class, len(variants)})
+	}
+	return "\x00" + strconv.Itoa(i) + "\x00"
+}
+
+// class returns the value of the class attribute of a marker
+func (s *stylesheet) class(use classUse) string {
+	if len(s.variants[use.class]) == 1 {
+		return use.class
+	}
+	return use.class + " " + variantName(use)
+}
+
+func variantName(use classUse) string {
+	return use.class + "-" + strconv.Itoa(use.variant)
+}
+
+// rule is a css rule for the selector of a class, which is either a
+// semantic class or one of its variants
+type rule struct {
+	class, semantic string
+	decls           []string
+}
+
+// rules returns the rules for every class. declarations shared by all
+// the variants of a class are given to the class itself
+func (s *stylesheet) rules() []rule {
+	var rules []rule
+	add := func(class, semantic string, decls []string) {
+		if len(decls) > 0 {
+			rules = append(rules, rule{class, semantic, decls})
+		}
+	}
+	for _, class := range s.classes {
+		variants := s.variants[class]
+		common := variants[0].Declarations()
+		for _, v := range variants[1:] {
+			common = intersect(common, v.Declarations())
+		}
+		add(class, class, common)
+		if len(variants) == 1 {
+			continue
+		}
+		for i, v := range variants {
+			add(variantName(classUse{class, i}), class, subtract(v.Declarations(), common))
+		}
+	}
+	return rules
+}
+
+func (s *stylesheet) css() string {
+	var b strings.Builder
+	for _, r := range s.rules() {
+		b.WriteString("." + r.class + "{" + strings.Join(r.decls, ";") + "}\n")
+	}
+	return cssEscaper.Replace(b.String())
+}
+
+// darkCSS returns the rules that recolour each class with `colours`
+// when the viewer prefers a dark colour scheme. the declarations are
+// marked important when they have to override inline styles
+func (s *stylesheet) darkCSS(colours ColourMap, important bool) string {
+	var b strings.Builder
+	for _, r := range s.rules() {
+		var decls []string
+		for _, decl := range r.decls {
+			i := strings.Index(decl, ":")
+			property, value := decl[:i], decl[i+1:]
+			if property != "fill" && property != "stroke" {
+				continue
+			}
+			dark, ok := colours.lookup(r.semantic, value)
+			if !ok || dark == value {
+				continue
+			}
+			if important {
+				dark += " !important"
+			}
+			decls = append(decls, property+":"+dark)
+		}
+		if len(decls) > 0 {
+			b.WriteString("." + r.class + "{" + strings.Join(decls, ";") + "}\n")
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return cssEscaper.Replace("@media (prefers-color-scheme: dark) {\n" + b.String() + "}\n")
+}
+
+// apply replaces the markers in `doc` with class names
+func (s *stylesheet) apply(doc []byte) []byte {
+	return classMarker.ReplaceAllFunc(doc, func(m []byte) []byte {
+		i, _ := strconv.Atoi(string(m[1 : len(m)-1]))
+		return []byte(s.class(s.uses[i]))
+	})
+}
+
+func intersect(a, b []string) []string {
+	var out []string
+	for _, x := range a {
+		if contains(b, x) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func subtract(a, b []string) []string {
+	var out []string
+	for _, x := range a {
+		if !contains(b, x) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
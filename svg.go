@@ -0,0 +1,326 @@
+package visualisations
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	svgo "github.com/ajstarks/svgo/float"
+)
+
+// SVG is a Canvas that writes an svg document
+type SVG struct {
+	// Precision is the number of decimals coordinates are written with,
+	// when nil each path keeps its own precision and everything else
+	// uses DefaultPrecision
+	Precision *int
+	// Minify writes the document as compactly as possible, see Minify
+	Minify bool
+	// Responsive scales the drawing to fit its container when set
+	Responsive *Responsive
+	// Classes gives elements their semantic class, such as gauge-fill,
+	// and moves their styles into a stylesheet at the start of the
+	// document so that pages can restyle them with css. elements of a
+	// class with different styles, such as the entries of a timeline,
+	// also get a numbered class such as timeline-entry-2
+	Classes bool
+	// InlineStyles keeps the styles on the elements as well when
+	// Classes is set
+	InlineStyles bool
+	// DarkColours maps colours to those used instead when the viewer
+	// prefers a dark colour scheme, through a media query in the
+	// stylesheet. only elements with a semantic class are recoloured,
+	// and they keep their inline styles unless Classes is set.
+	// visualisations with a dark theme add its colours, which don't
+	// replace any already here
+	DarkColours ColourMap
+	// Fonts are embedded in the document as @font-face rules, subsetted
+	// to the text drawn in them where the font allows. text uses a font
+	// when its Family is the first family of the text's style
+	Fonts []*Font
+	// fontText is the text drawn in each of Fonts
+	fontText []strings.Builder
+	w        *ErrWriter
+	canvas   *svgo.SVG
+	buf      bytes.Buffer
+	sheet    *stylesheet
+	// defined are the ids of the paints and filters already defined
+	defined map[string]bool
+	// sheetAt is where the stylesheet goes in the buffered document
+	sheetAt int
+	// title and desc describe the next drawing
+	title, desc string
+}
+
+// Responsive controls how a drawing scales to fit its container. the
+// drawing is given a viewBox of its preferred size, so the coordinates
+// within it are unchanged
+type Responsive struct {
+	// FullWidth sets the width to 100% and leaves out the height, so
+	// that the drawing fills the width of its container and keeps its
+	// aspect ratio
+	FullWidth bool
+	// PreserveAspectRatio is the svg attribute of the same name, such as
+	// "xMidYMid meet" or "none" to stretch the drawing. defaults to
+	// centring the whole drawing
+	PreserveAspectRatio string
+}
+
+// NewSVG creates a canvas writing to `out`
+func NewSVG(out io.Writer) *SVG {
+	w := NewErrWriter(out)
+	return &SVG{w: w, canvas: svgo.New(w)}
+}
+
+func (s *SVG) Start(width, height float64) {
+	s.canvas.Decimals = DefaultPrecision
+	if s.Precision != nil {
+		s.canvas.Decimals = *s.Precision
+	}
+	// minified and classed documents are written out once they are
+	// complete
+	s.canvas.Writer = s.w
+	s.buf.Reset()
+	if s.Minify || s.stylesheet() {
+		s.canvas.Writer = &s.buf
+	}
+	s.sheet = newStylesheet()
+	s.fontText = make([]strings.Builder, len(s.Fonts))
+	s.defined = map[string]bool{}
+	var attrs []string
+	if r := s.Responsive; r != nil {
+		d := s.canvas.Decimals
+		attrs = append(attrs, fmt.Sprintf(`viewBox="0 0 %.*f %.*f"`, d, width, d, height))
+		if r.PreserveAspectRatio != "" {
+			attrs = append(attrs, fmt.Sprintf(`preserveAspectRatio="%s"`,
+				escape(r.PreserveAspectRatio)))
+		}
+	}
+	// the title and description label the image for screen readers
+	id := ""
+	if s.title != "" || s.desc != "" {
+		id = describedID(s.title, s.desc)
+		var labels []string
+		if s.title != "" {
+			labels = append(labels, id+"-title")
+		}
+		if s.desc != "" {
+			labels = append(labels, id+"-desc")
+		}
+		attrs = append(attrs, `role="img"`,
+			fmt.Sprintf(`aria-labelledby="%s"`, strings.Join(labels, " ")))
+	}
+	if s.Responsive != nil && s.Responsive.FullWidth {
+		// Start always writes a height, which would fix the height of
+		// the drawing however wide it is made
+		s.canvas.Startraw(append([]string{`width="100%"`}, attrs...)...)
+	} else {
+		s.canvas.Start(width, height, attrs...)
+	}
+	if id != "" {
+		s.element("title", id+"-title", s.title)
+		s.element("desc", id+"-desc", s.desc)
+		s.title, s.desc = "", ""
+	}
+	s.sheetAt = s.buf.Len()
+}
+
+// DarkMode adds `colours` to DarkColours for the next drawing
+func (s *SVG) DarkMode(colours ColourMap) {
+	if s.DarkColours == nil {
+		s.DarkColours = ColourMap{}
+	}
+	for light, dark := range colours {
+		if _, ok := s.DarkColours[light]; !ok {
+			s.DarkColours[light] = dark
+		}
+	}
+}
+
+// classed reports whether elements are given classes
+func (s *SVG) classed() bool {
+	return s.Classes || len(s.DarkColours) > 0
+}
+
+// stylesheet reports whether the document has a stylesheet, so has to
+// be buffered until it is complete
+func (s *SVG) stylesheet() bool {
+	return s.classed() || len(s.Fonts) > 0
+}
+
+// inline reports whether classed elements keep their inline styles
+func (s *SVG) inline() bool {
+	return s.InlineStyles || !s.Classes
+}
+
+// Describe gives the next drawing a title and description, which are
+// written at its start along with the aria attributes that link them
+func (s *SVG) Describe(title, desc string) {
+	s.title, s.desc = title, desc
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// element writes a text only element, unless `text` is empty
+func (s *SVG) element(tag, id, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(s.canvas.Writer, `<%s id="%s">`, tag, id)
+	xml.EscapeText(s.canvas.Writer, []byte(text))
+	fmt.Fprintf(s.canvas.Writer, "</%s>\n", tag)
+}
+
+func (s *SVG) End() error {
+	s.canvas.End()
+	doc := s.buf.Bytes()
+	if s.stylesheet() {
+		var classed bytes.Buffer
+		classed.Write(s.sheet.apply(doc[:s.sheetAt]))
+		css := ""
+		for i, font := range s.Fonts {
+			if s.fontText[i].Len() == 0 {
+				continue
+			}
+			face, err := font.face(s.fontText[i].String())
+			if err != nil {
+				return err
+			}
+			css += face
+		}
+		if s.Classes {
+			css += s.sheet.css()
+		}
+		css += s.sheet.darkCSS(s.DarkColours, s.inline())
+		if css != "" {
+			classed.WriteString("<style>\n" + css + "</style>\n")
+		}
+		classed.Write(s.sheet.apply(doc[s.sheetAt:]))
+		doc = classed.Bytes()
+	}
+	switch {
+	case s.Minify:
+		if err := Minify(s.w, bytes.NewReader(doc)); err != nil {
+			return err
+		}
+	case s.stylesheet():
+		s.w.Write(doc)
+	}
+	return s.w.Err()
+}
+
+// style returns the attributes svgo writes for `style`, which are either
+// an inline style or a class attribute. any paints and filters are
+// defined first
+func (s *SVG) style(style Style) []string {
+	if style.FillPaint != nil {
+		style.Fill = "url(#" + s.define(style.FillPaint) + ")"
+	}
+	if style.StrokePaint != nil {
+		style.Stroke = "url(#" + s.define(style.StrokePaint) + ")"
+	}
+	var attrs []string
+	if !s.classed() || style.Class == "" {
+		attrs = append(attrs, style.String())
+	} else {
+		attrs = append(attrs, `class="`+s.sheet.marker(style)+`"`)
+		if s.inline() {
+			attrs = append(attrs, style.String())
+		}
+	}
+	if style.Filter != nil {
+		attrs = append(attrs, `filter="url(#`+s.define(style.Filter)+`)"`)
+	}
+	return attrs
+}
+
+// definer is anything written once in <defs> and referred to by id
+type definer interface {
+	definition() (def, id string)
+}
+
+// define writes the definition of `d` unless it already has been, and
+// returns its id
+func (s *SVG) define(d definer) string {
+	def, id := d.definition()
+	if !s.defined[id] {
+		fmt.Fprint(s.canvas.Writer, "<defs>\n"+def+"</defs>\n")
+		s.defined[id] = true
+	}
+	return id
+}
+
+func (s *SVG) Group(id string) {
+	if id == "" {
+		s.canvas.Group()
+		return
+	}
+	s.canvas.Gid(id)
+}
+
+func (s *SVG) Translate(x, y float64) {
+	s.canvas.Translate(x, y)
+}
+
+func (s *SVG) TranslateRotate(x, y, degrees float64) {
+	s.canvas.TranslateRotate(x, y, degrees)
+}
+
+func (s *SVG) EndGroup() {
+	s.canvas.Gend()
+}
+
+func (s *SVG) Title(text string) {
+	s.canvas.Title(text)
+}
+
+func (s *SVG) Line(x1, y1, x2, y2 float64, style Style) {
+	s.canvas.Line(x1, y1, x2, y2, s.style(style)...)
+}
+
+func (s *SVG) Polyline(xs, ys []float64, style Style) {
+	s.canvas.Polyline(xs, ys, s.style(style)...)
+}
+
+func (s *SVG) Path(p *Path, style Style) {
+	if s.Precision != nil {
+		rounded := *p
+		rounded.Precision = *s.Precision
+		p = &rounded
+	}
+	s.canvas.Path(p.String(), s.style(style)...)
+}
+
+func (s *SVG) Circle(cx, cy, r float64, style Style) {
+	s.canvas.Circle(cx, cy, r, s.style(style)...)
+}
+
+func (s *SVG) Rect(x, y, width, height float64, style Style) {
+	s.canvas.Rect(x, y, width, height, s.style(style)...)
+}
+
+func (s *SVG) Text(x, y float64, text string, style Style) {
+	family := firstFamily(style.FontFamily)
+	for i, font := range s.Fonts {
+		if strings.EqualFold(font.Family, family) {
+			s.fontText[i].WriteString(text)
+			break
+		}
+	}
+	s.canvas.Text(x, y, text, s.style(style)...)
+}
+
+func (s *SVG) Animate(target, attribute string, from, to, duration float64, repeat int) {
+	count := "indefinite"
+	if repeat > 0 {
+		count = fmt.Sprint(repeat)
+	}
+	fmt.Fprintf(s.canvas.Writer, `<animate xlink:href="#%s" attributeName="%s" from="%g" to="%g" dur="%gs" repeatCount="%s" />`+"\n",
+		target, attribute, from, to, duration, count)
+}
@@ -0,0 +1,94 @@
+package term
+
+import (
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/gauge"
+)
+
+// eighths are the blocks for the part filled end of a bar
+var eighths = []rune(" ▏▎▍▌▋▊▉")
+
+// Bar draws a gauge compactly as a single line bar followed by its
+// label, filling the width of the options
+func Bar(out io.Writer, g gauge.GaugeOptions, opts Options) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	g = g.Resolved()
+	cols, _ := opts.size()
+	width := cols
+	if g.Label != "" {
+		width -= textWidth(g.Label) + 1
+	}
+	var line strings.Builder
+	if opts.Colour == ColourNone {
+		width -= 2
+		if width < 1 {
+			width = 1
+		}
+		filled := int(math.Round(g.FillProportion * float64(width)))
+		line.WriteString("[" + strings.Repeat("#", filled) +
+			strings.Repeat("-", width-filled) + "]")
+	} else {
+		if width < 1 {
+			width = 1
+		}
+		// the bar is measured in eighths of a column
+		n := int(math.Round(g.FillProportion * float64(width*8)))
+		full, part := n/8, n%8
+		empty := width - full
+		line.WriteString(colourEscape(g.Colour, opts.Colour))
+		line.WriteString(strings.Repeat("█", full))
+		if part > 0 {
+			// the rest of a part filled column is track coloured
+			line.WriteString(backgroundEscape(g.BackgroundColour, opts.Colour))
+			line.WriteRune(eighths[part])
+			line.WriteString("\x1b[49m")
+			empty--
+		}
+		line.WriteString(colourEscape(g.BackgroundColour, opts.Colour))
+		line.WriteString(strings.Repeat("░", empty))
+		line.WriteString("\x1b[0m")
+	}
+	if g.Label != "" {
+		label := " " + g.Label
+		if opts.Colour != ColourNone && g.LabelColour != "" {
+			label = " " + colourEscape(g.LabelColour, opts.Colour) + g.Label + "\x1b[0m"
+		}
+		line.WriteString(label)
+	}
+	w := visual.NewErrWriter(out)
+	io.WriteString(w, line.String()+"\n")
+	return w.Err()
+}
+
+func parse(s string) (color.NRGBA, bool) {
+	c, err := visual.ParseColour(s)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	r, g, b, a := c.RGBA8()
+	return color.NRGBA{R: r, G: g, B: b, A: a}, true
+}
+
+// colourEscape selects the css colour `s` as the foreground, or the
+// terminal default if it can't be parsed
+func colourEscape(s string, mode ColourMode) string {
+	if c, ok := parse(s); ok {
+		return escape(c, mode)
+	}
+	return "\x1b[39m"
+}
+
+// backgroundEscape selects the css colour `s` as the background
+func backgroundEscape(s string, mode ColourMode) string {
+	if c, ok := parse(s); ok {
+		return "\x1b[4" + escape(c, mode)[3:]
+	}
+	return "\x1b[49m"
+}
@@ -0,0 +1,364 @@
+// Package term draws visualisations in a terminal. charts are drawn
+// with braille dots, or plain ascii when colour is turned off, with
+// their labels kept as text
+//
+//	term.Render(os.Stdout, timeline.TimelineOptions{...}, term.Options{
+//		Width:  80,
+//		Colour: term.DetectColour(),
+//	})
+package term
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/raster"
+)
+
+type ColourMode int
+
+const (
+	// ColourNone draws in plain ascii without escape codes
+	ColourNone ColourMode = iota
+	// Colour256 uses the xterm 256 colour palette
+	Colour256
+	// ColourTrue uses 24 bit colour
+	ColourTrue
+)
+
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+	// threshold is the coverage above which a dot is drawn
+	threshold = 0.25
+)
+
+// Options control the size and colours of the output
+type Options struct {
+	// Width and Height are the most columns and rows to use, they
+	// default to 80 by 24
+	Width  int
+	Height int
+	Colour ColourMode
+}
+
+func (o Options) size() (int, int) {
+	w, h := o.Width, o.Height
+	if w <= 0 {
+		w = defaultWidth
+	}
+	if h <= 0 {
+		h = defaultHeight
+	}
+	return w, h
+}
+
+// DetectColour picks the colour mode from the environment, following
+// the NO_COLOR, COLORTERM and TERM conventions
+func DetectColour() ColourMode {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return ColourNone
+	}
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return ColourTrue
+	}
+	if t := os.Getenv("TERM"); t == "" || t == "dumb" {
+		return ColourNone
+	}
+	return Colour256
+}
+
+// cell is a single character of the output
+type cell struct {
+	text   rune
+	colour color.NRGBA
+	// dots are the braille dots that are set, as in the bits of the
+	// unicode braille patterns
+	dots uint8
+}
+
+// Canvas is a visual.Canvas that draws into a grid of characters. the
+// drawing is rasterised at two dots across and four down per
+// character, and text is placed into the nearest characters
+type Canvas struct {
+	*raster.Canvas
+	Options
+	texts []text
+}
+
+type text struct {
+	x, y   float64
+	s      string
+	anchor string
+	colour color.NRGBA
+}
+
+// NewCanvas creates a canvas that fits drawings within `opts`
+func NewCanvas(opts Options) *Canvas {
+	return &Canvas{Options: opts}
+}
+
+// Start scales the drawing to fit within the columns and rows of the
+// options, keeping dots square
+func (c *Canvas) Start(width, height float64) {
+	cols, rows := c.size()
+	scale := 1.0
+	if width > 0 && height > 0 {
+		scale = math.Min(float64(cols*2)/width, float64(rows*4)/height)
+	}
+	c.texts = nil
+	c.Canvas = raster.NewCanvas(scale)
+	c.Canvas.Start(width, height)
+}
+
+// Rect is drawn unless it is a theme's background, as the terminal has
+// its own
+func (c *Canvas) Rect(x, y, width, height float64, style visual.Style) {
+	if style.Class == "background" {
+		return
+	}
+	c.Canvas.Rect(x, y, width, height, style)
+}
+
+// Text keeps the text to be written out as characters
+func (c *Canvas) Text(x, y float64, s string, style visual.Style) {
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	col, err := visual.ParseColour(fill)
+	if err != nil || s == "" {
+		return
+	}
+	ix, iy := c.ToImage(x, y)
+	r, g, b, a := col.RGBA8()
+	c.texts = append(c.texts, text{
+		x:      ix,
+		y:      iy,
+		s:      s,
+		anchor: style.TextAnchor,
+		colour: color.NRGBA{R: r, G: g, B: b, A: a},
+	})
+}
+
+// End does nothing, use WriteTo to write the characters out
+func (c *Canvas) End() error {
+	return nil
+}
+
+// braille maps a dot at column x and row y of a cell onto its bit
+var braille = [4][2]uint8{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+func (c *Canvas) cells() [][]cell {
+	img := c.Image()
+	b := img.Bounds()
+	cols, rows := (b.Dx()+1)/2, (b.Dy()+3)/4
+	grid := make([][]cell, rows)
+	for row := range grid {
+		grid[row] = make([]cell, cols)
+		for col := range grid[row] {
+			grid[row][col] = dotCell(img, col, row)
+		}
+	}
+	for _, t := range c.texts {
+		row := int(t.y / 4)
+		if row < 0 || row >= rows {
+			continue
+		}
+		n := textWidth(t.s)
+		col := int(math.Round(t.x / 2))
+		switch t.anchor {
+		case "middle":
+			col -= n / 2
+		case "end":
+			col -= n
+		}
+		for _, r := range t.s {
+			if col >= 0 && col < cols {
+				grid[row][col] = cell{text: r, colour: t.colour}
+			}
+			col++
+			if isWide(r) && col < cols {
+				// the next column is covered by the wide character
+				grid[row][col] = cell{text: -1}
+				col++
+			}
+		}
+	}
+	return grid
+}
+
+// dotCell reads the dots of the cell at `col`, `row` from the image,
+// coloured by its most solid pixel so that anti-aliased edges don't
+// tint it
+func dotCell(img *image.RGBA, col, row int) cell {
+	var c cell
+	var solid color.RGBA
+	for dy := 0; dy < 4; dy++ {
+		for dx := 0; dx < 2; dx++ {
+			p := img.RGBAAt(col*2+dx, row*4+dy)
+			if float64(p.A)/255 < threshold {
+				continue
+			}
+			c.dots |= braille[dy][dx]
+			if p.A > solid.A {
+				solid = p
+			}
+		}
+	}
+	if c.dots != 0 {
+		c.colour = color.NRGBAModel.Convert(solid).(color.NRGBA)
+		c.colour.A = 255
+	}
+	return c
+}
+
+// WriteTo writes the drawing out as lines of text
+func (c *Canvas) WriteTo(out io.Writer) (int64, error) {
+	w := visual.NewErrWriter(out)
+	grid := c.cells()
+	// drop empty rows from the bottom
+	for len(grid) > 0 && blank(grid[len(grid)-1]) {
+		grid = grid[:len(grid)-1]
+	}
+	for _, row := range grid {
+		var line strings.Builder
+		var current color.NRGBA
+		for _, cl := range row {
+			ch := cl.text
+			switch {
+			case ch == -1:
+				continue
+			case ch == 0 && cl.dots == 0:
+				ch = ' '
+			case ch == 0 && c.Colour == ColourNone:
+				ch = asciiDots(cl.dots)
+			case ch == 0:
+				ch = rune(0x2800 + int(cl.dots))
+			}
+			if c.Colour != ColourNone && ch != ' ' && cl.colour != current {
+				line.WriteString(escape(cl.colour, c.Colour))
+				current = cl.colour
+			}
+			line.WriteRune(ch)
+		}
+		text := strings.TrimRight(line.String(), " ")
+		if current != (color.NRGBA{}) {
+			text += "\x1b[0m"
+		}
+		io.WriteString(w, text+"\n")
+	}
+	return w.Written(), w.Err()
+}
+
+func blank(row []cell) bool {
+	for _, c := range row {
+		if c.text != 0 || c.dots != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiDots picks an ascii character shaped roughly like `dots`
+func asciiDots(dots uint8) rune {
+	left := dots & 0x47
+	right := dots & 0xb8
+	top := dots & 0x1b
+	bottom := dots & 0xe4
+	count := 0
+	for d := dots; d != 0; d &= d - 1 {
+		count++
+	}
+	switch {
+	case count >= 6:
+		return '#'
+	case (left == 0 || right == 0) && top != 0 && bottom != 0:
+		return '|'
+	case left != 0 && right != 0 && top == 0:
+		return '_'
+	case left != 0 && right != 0 && bottom == 0:
+		return '-'
+	case dots&0x08 != 0 && dots&0x40 != 0:
+		return '/'
+	case dots&0x01 != 0 && dots&0x80 != 0:
+		return '\\'
+	case top == 0:
+		return '.'
+	case bottom == 0:
+		return '\''
+	}
+	return '*'
+}
+
+// escape returns the escape code selecting `c` as the foreground
+func escape(c color.NRGBA, mode ColourMode) string {
+	if mode == ColourTrue {
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm", xterm256(c))
+}
+
+// xterm256 finds the closest colour in the 6x6x6 cube or grey ramp of
+// the xterm palette
+func xterm256(c color.NRGBA) int {
+	level := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (int(v) - 35) / 40
+	}
+	r, g, b := level(c.R), level(c.G), level(c.B)
+	cube := 16 + 36*r + 6*g + b
+	if r == g && g == b {
+		// greys are closer on the finer grey ramp
+		avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+		if avg > 8 && avg < 238 {
+			return 232 + (avg-8)/10
+		}
+	}
+	return cube
+}
+
+func isWide(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0xff01 && r <= 0xff60) || (r >= 0x1f300 && r <= 0x1faff)
+}
+
+// textWidth is the number of columns `s` takes up
+func textWidth(s string) int {
+	n := utf8.RuneCountInString(s)
+	for _, r := range s {
+		if isWide(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// Render draws `d` to fit the terminal described by `opts`
+func Render(out io.Writer, d visual.Drawer, opts Options) error {
+	c := NewCanvas(opts)
+	if err := visual.DrawTo(c, d); err != nil {
+		return err
+	}
+	_, err := c.WriteTo(out)
+	return err
+}
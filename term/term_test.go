@@ -0,0 +1,199 @@
+package term
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/gauge"
+	"github.com/osraige/visualisations/timeline"
+)
+
+func TestBar(t *testing.T) {
+	for _, testcase := range []struct {
+		name    string
+		options gauge.GaugeOptions
+		colour  ColourMode
+		want    string
+	}{
+		{
+			name:    "ascii",
+			options: gauge.GaugeOptions{Size: 100, FillProportion: 0.5, Label: "50%"},
+			want:    "[#######-------] 50%\n",
+		},
+		{
+			name:    "ascii full",
+			options: gauge.GaugeOptions{Size: 100, FillProportion: 1},
+			want:    "[##################]\n",
+		},
+		{
+			name: "true colour",
+			options: gauge.GaugeOptions{
+				Size:             100,
+				FillProportion:   0.25,
+				Colour:           "#ff0000",
+				BackgroundColour: "#0000ff",
+			},
+			colour: ColourTrue,
+			want: "\x1b[38;2;255;0;0m█████\x1b[38;2;0;0;255m" +
+				"░░░░░░░░░░░░░░░\x1b[0m\n",
+		},
+		{
+			name: "part filled column",
+			options: gauge.GaugeOptions{
+				Size:             100,
+				FillProportion:   0.12,
+				Colour:           "#ff0000",
+				BackgroundColour: "#0000ff",
+			},
+			colour: Colour256,
+			want: "\x1b[38;5;196m██\x1b[48;5;21m▍\x1b[49m\x1b[38;5;21m" +
+				"░░░░░░░░░░░░░░░░░\x1b[0m\n",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Bar(&out, testcase.options, Options{Width: 20, Colour: testcase.colour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != testcase.want {
+				t.Errorf("got %q, want %q", got, testcase.want)
+			}
+		})
+	}
+}
+
+func TestBarInvalid(t *testing.T) {
+	err := Bar(&bytes.Buffer{}, gauge.GaugeOptions{Size: 100, FillProportion: 2}, Options{})
+	var verr *visual.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	options := timeline.TimelineOptions{
+		SegmentLength: 40,
+		LineWidth:     3,
+		GapWidth:      10,
+		GapHeight:     30,
+		PaddingX:      10,
+		PaddingY:      20,
+		LabelFontSize: 12,
+		Entries:       [][]string{{"alpha", "beta"}, {"alpha"}},
+		ColumnLabels:  []string{"mon", "tue"},
+		GetColour: func(label string) string {
+			if label == "alpha" {
+				return "#4e79a7"
+			}
+			return "#f28e2b"
+		},
+	}
+	for _, testcase := range []struct {
+		name   string
+		colour ColourMode
+		check  func(t *testing.T, out string)
+	}{
+		{
+			name: "ascii",
+			check: func(t *testing.T, out string) {
+				for _, r := range out {
+					if r > '~' && r != '\n' {
+						t.Fatalf("got non ascii %q in\n%s", r, out)
+					}
+				}
+			},
+		},
+		{
+			name:   "true colour",
+			colour: ColourTrue,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, "\x1b[38;2;78;121;167m") {
+					t.Errorf("missing entry colour in %q", out)
+				}
+				braille := strings.IndexFunc(out, func(r rune) bool {
+					return r > 0x2800 && r <= 0x28ff
+				})
+				if braille < 0 {
+					t.Errorf("missing braille in %q", out)
+				}
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Render(&out, options, Options{Width: 40, Height: 12, Colour: testcase.colour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if len(lines) > 12 {
+				t.Errorf("got %d lines, want at most 12", len(lines))
+			}
+			for _, label := range []string{"alpha", "beta", "mon", "tue"} {
+				if !strings.Contains(out.String(), label) {
+					t.Errorf("missing label %q in\n%s", label, out.String())
+				}
+			}
+			testcase.check(t, out.String())
+		})
+	}
+}
+
+func TestRenderThemedGaugeSet(t *testing.T) {
+	set := gauge.GaugeSet{
+		{Size: 100, FillProportion: 0.25, Label: "CPU", Theme: &visual.LightTheme},
+		{Size: 100, FillProportion: 0.75, Label: "RAM", Theme: &visual.LightTheme},
+	}
+	var out bytes.Buffer
+	if err := Render(&out, set, Options{Width: 40, Height: 12}); err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{"CPU", "RAM"} {
+		if !strings.Contains(out.String(), label) {
+			t.Errorf("missing label %q in\n%s", label, out.String())
+		}
+	}
+	// each gauge's background is left to the terminal rather than
+	// filling every row
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if !strings.Contains(line, " ") {
+			t.Fatalf("background drawn in\n%s", out.String())
+		}
+	}
+}
+
+func TestAsciiDots(t *testing.T) {
+	for _, testcase := range []struct {
+		dots uint8
+		want rune
+	}{
+		{0xff, '#'},
+		{0x01 | 0x40, '|'},
+		{0x40 | 0x80, '_'},
+	} {
+		if got := asciiDots(testcase.dots); got != testcase.want {
+			t.Errorf("asciiDots(%#x) = %q, want %q", testcase.dots, got, testcase.want)
+		}
+	}
+}
+
+func TestXterm256(t *testing.T) {
+	for _, testcase := range []struct {
+		colour color.NRGBA
+		want   int
+	}{
+		{color.NRGBA{R: 0, G: 0, B: 0}, 16},
+		{color.NRGBA{R: 255, G: 255, B: 255}, 231},
+		{color.NRGBA{R: 255, G: 0, B: 0}, 196},
+		{color.NRGBA{R: 128, G: 128, B: 128}, 244},
+	} {
+		if got := xterm256(testcase.colour); got != testcase.want {
+			t.Errorf("xterm256(%v) = %d, want %d", testcase.colour, got, testcase.want)
+		}
+	}
+}
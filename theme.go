@@ -0,0 +1,174 @@
+package visualisations
+
+import (
+	"sort"
+	"strings"
+)
+
+// Theme holds the presentation defaults shared by the visualisations.
+// any option a visualisation leaves unset falls back to its theme
+type Theme struct {
+	// FontFamily is the font stack used for all text
+	FontFamily string
+	// FontSize is the size of labels in pixels
+	FontSize int
+	// TextColour is used for primary text such as labels
+	TextColour Colour
+	// MutedTextColour is used for secondary text such as column labels
+	MutedTextColour Colour
+	// Background fills the whole image, a transparent background is
+	// not drawn
+	Background Colour
+	// Primary is the main data colour
+	Primary Colour
+	// Accent is used for secondary data such as averages
+	Accent Colour
+	// LineWidth is the default width of lines
+	LineWidth float64
+	// Palette colours categorical data
+	Palette Palette
+}
+
+var (
+	LightTheme = Theme{
+		FontFamily:      "Helvetica, Arial, sans-serif",
+		FontSize:        12,
+		TextColour:      hexColour(0x222222),
+		MutedTextColour: hexColour(0x888888),
+		Background:      White,
+		Primary:         hexColour(0x4e79a7),
+		Accent:          hexColour(0xf28e2b),
+		LineWidth:       3,
+		Palette:         Tableau10,
+	}
+	DarkTheme = Theme{
+		FontFamily:      "Helvetica, Arial, sans-serif",
+		FontSize:        12,
+		TextColour:      hexColour(0xeeeeee),
+		MutedTextColour: hexColour(0x999999),
+		Background:      hexColour(0x1e1e1e),
+		Primary:         hexColour(0x76b7b2),
+		Accent:          hexColour(0xf28e2b),
+		LineWidth:       3,
+		Palette:         Set2,
+	}
+)
+
+// HasBackground reports whether the theme's background should be drawn
+func (t Theme) HasBackground() bool {
+	return t.Background.A > 0
+}
+
+// Track is the colour for the unfilled part of a shape drawn in
+// Primary, such as the empty portion of a gauge
+func (t Theme) Track() Colour {
+	bg := t.Background
+	if !t.HasBackground() {
+		bg = White
+	}
+	return t.Primary.Mix(bg, 0.8)
+}
+
+// BackgroundStyle is the style of the rectangle filling the background
+func (t Theme) BackgroundStyle() Style {
+	return Style{Fill: t.Background.String(), Class: "background"}
+}
+
+// ColourMap maps the colours of a drawing to the colours it takes on
+// when the viewer prefers a dark colour scheme. a colour written as
+// ".class colour" is only mapped for elements of that class, and takes
+// precedence over the colour on its own
+type ColourMap map[string]string
+
+// paletteCycles is how many times round a palette DarkColours pairs
+// colours, as each pass of a palette is a darker shade
+const paletteCycles = 4
+
+// DarkColours pairs each colour of the theme with the colour in the same
+// role in `dark`, such as the text colours of both. a background `dark`
+// leaves transparent is mapped to none. palette colours that are also
+// used for a role, as Primary and Accent often are, follow the role.
+// see DarkPalette for elements coloured from the palette
+func (t Theme) DarkColours(dark Theme) ColourMap {
+	m := ColourMap{}
+	pair := m.pairer("")
+	pair(t.Background, dark.Background)
+	pair(t.TextColour, dark.TextColour)
+	pair(t.MutedTextColour, dark.MutedTextColour)
+	pair(t.Primary, dark.Primary)
+	pair(t.Accent, dark.Accent)
+	pair(t.Track(), dark.Track())
+	t.pairPalette(pair, dark)
+	return m
+}
+
+// DarkPalette pairs the palette of the theme with the palette of `dark`
+// for the elements of `classes` only, so that categorical colours all
+// follow the dark palette even where they are also role colours
+func (t Theme) DarkPalette(dark Theme, classes ...string) ColourMap {
+	m := ColourMap{}
+	for _, class := range classes {
+		t.pairPalette(m.pairer("."+class+" "), dark)
+	}
+	return m
+}
+
+func (t Theme) pairPalette(pair func(light, dark Colour), dark Theme) {
+	for i := 0; i < paletteCycles*len(t.Palette); i++ {
+		pair(t.Palette.At(i), dark.Palette.At(i))
+	}
+}
+
+// pairer returns a function that maps a light colour to a dark one
+// under `scope`, unless the light colour is already mapped
+func (m ColourMap) pairer(scope string) func(light, dark Colour) {
+	return func(light, dark Colour) {
+		if light.A == 0 {
+			return
+		}
+		key := scope + light.String()
+		if _, ok := m[key]; ok {
+			return
+		}
+		m[key] = "none"
+		if dark.A > 0 {
+			m[key] = dark.String()
+		}
+	}
+}
+
+// lookup returns the colour `colour` is mapped to for elements of
+// `class`, matching colours written differently such as "#FFF" and
+// "white"
+func (m ColourMap) lookup(class, colour string) (string, bool) {
+	if class != "" {
+		if dark, ok := m.match("."+class+" ", colour); ok {
+			return dark, true
+		}
+	}
+	return m.match("", colour)
+}
+
+// match looks up `colour` among the colours mapped under `scope`
+func (m ColourMap) match(scope, colour string) (string, bool) {
+	if dark, ok := m[scope+colour]; ok {
+		return dark, true
+	}
+	c, err := ParseColour(colour)
+	if err != nil {
+		return "", false
+	}
+	var lights []string
+	for key := range m {
+		if strings.HasPrefix(key, scope) && !strings.HasPrefix(key[len(scope):], ".") {
+			lights = append(lights, key[len(scope):])
+		}
+	}
+	sort.Strings(lights)
+	for _, light := range lights {
+		if l, err := ParseColour(light); err == nil && l.String() == c.String() {
+			return m[scope+light], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package visualisations
+
+import "testing"
+
+func TestDarkColours(t *testing.T) {
+	colours := LightTheme.DarkColours(DarkTheme)
+	for _, testcase := range []struct {
+		light string
+		want  string
+	}{
+		{light: "#ffffff", want: "#1e1e1e"},
+		{light: "white", want: "#1e1e1e"},
+		{light: "#222", want: "#eeeeee"},
+		{light: LightTheme.Primary.String(), want: DarkTheme.Primary.String()},
+		{light: LightTheme.Accent.String(), want: DarkTheme.Accent.String()},
+		{light: LightTheme.Track().String(), want: DarkTheme.Track().String()},
+		{light: Tableau10.At(2).String(), want: Set2.At(2).String()},
+		{light: Tableau10.At(12).String(), want: Set2.At(12).String()},
+		{light: "red", want: ""},
+	} {
+		if got, _ := colours.lookup("", testcase.light); got != testcase.want {
+			t.Errorf("lookup(%q) = %q, want %q", testcase.light, got, testcase.want)
+		}
+	}
+
+	transparent := DarkTheme
+	transparent.Background = Colour{}
+	if got := LightTheme.DarkColours(transparent)["#ffffff"]; got != "none" {
+		t.Errorf("transparent background mapped to %q, want none", got)
+	}
+}
+
+func TestDarkPalette(t *testing.T) {
+	colours := LightTheme.DarkColours(DarkTheme)
+	for light, dark := range LightTheme.DarkPalette(DarkTheme, "entry") {
+		colours[light] = dark
+	}
+	for _, testcase := range []struct {
+		class, light string
+		want         string
+	}{
+		// the first palette colours are also Primary and Accent
+		{class: "entry", light: Tableau10.At(0).String(), want: Set2.At(0).String()},
+		{class: "entry", light: "#F28E2B", want: Set2.At(1).String()},
+		{class: "fill", light: Tableau10.At(0).String(), want: DarkTheme.Primary.String()},
+		{class: "entry", light: "#222", want: "#eeeeee"},
+	} {
+		if got, _ := colours.lookup(testcase.class, testcase.light); got != testcase.want {
+			t.Errorf("lookup(%q, %q) = %q, want %q", testcase.class, testcase.light, got, testcase.want)
+		}
+	}
+}
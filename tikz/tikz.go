@@ -0,0 +1,324 @@
+// Package tikz writes visualisations as tikz pictures for including in
+// latex documents, so that labels are set in the document's own fonts
+//
+//	\input{clock.tex}
+//
+// the picture needs the tikz package, which loads xcolor for the
+// colour definitions written before it
+package tikz
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/measure"
+)
+
+// fontCommands maps the families of package measure onto the latex
+// font switches for the document's families
+var fontCommands = map[string]string{
+	measure.SansSerif.Name: `\sffamily`,
+	measure.Serif.Name:     `\rmfamily`,
+	measure.Monospace.Name: `\ttfamily`,
+}
+
+// specials are the latex special characters and how to write them
+// as text
+var specials = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// Picture is a visual.Canvas that writes a tikzpicture environment. one
+// unit is a point, with y pointing down to match svg
+type Picture struct {
+	visual.Static
+	// Standalone wraps the picture in a document of the standalone
+	// class so that it can be compiled by itself
+	Standalone bool
+	// RawText writes labels as latex rather than escaping them, so that
+	// they can hold maths and macros
+	RawText bool
+	out     io.Writer
+	body    bytes.Buffer
+	colours []string
+	indent  int
+}
+
+// NewPicture creates a picture that is written to `out` when it ends
+func NewPicture(out io.Writer) *Picture {
+	return &Picture{out: out}
+}
+
+// Write writes `d` as a tikzpicture
+func Write(out io.Writer, d visual.Drawer) error {
+	return visual.DrawTo(NewPicture(out), d)
+}
+
+func (p *Picture) printf(format string, args ...interface{}) {
+	p.body.WriteString(strings.Repeat("  ", p.indent))
+	fmt.Fprintf(&p.body, format, args...)
+	p.body.WriteByte('\n')
+}
+
+func (p *Picture) Start(width, height float64) {
+	p.body.Reset()
+	p.colours = nil
+	p.indent = 1
+	// an invisible rectangle keeps the bounding box of the picture the
+	// same as the svg
+	p.printf(`\useasboundingbox (0,0) rectangle (%s,%s);`, num(width), num(height))
+}
+
+// End writes the colour definitions followed by the picture
+func (p *Picture) End() error {
+	w := visual.NewErrWriter(p.out)
+	if p.Standalone {
+		io.WriteString(w, "\\documentclass[tikz]{standalone}\n\\begin{document}\n")
+	}
+	for i, c := range p.colours {
+		fmt.Fprintf(w, "\\definecolor{%s}{HTML}{%s}\n", colourName(i), c)
+	}
+	io.WriteString(w, "\\begin{tikzpicture}[x=1pt, y=-1pt, miter limit=4]\n")
+	w.Write(p.body.Bytes())
+	io.WriteString(w, "\\end{tikzpicture}\n")
+	if p.Standalone {
+		io.WriteString(w, "\\end{document}\n")
+	}
+	return w.Err()
+}
+
+func (p *Picture) scope(options string) {
+	if options == "" {
+		p.printf(`\begin{scope}`)
+	} else {
+		p.printf(`\begin{scope}[%s]`, options)
+	}
+	p.indent++
+}
+
+// Group starts a scope, with `id` kept as a comment
+func (p *Picture) Group(id string) {
+	if id != "" {
+		p.printf("%% %s", id)
+	}
+	p.scope("")
+}
+
+func (p *Picture) Translate(x, y float64) {
+	p.scope(fmt.Sprintf("shift={(%s,%s)}", num(x), num(y)))
+}
+
+// TranslateRotate starts a scope moved to `x`, `y` and rotated. tikz
+// rotates anticlockwise, which is clockwise once y is flipped. text
+// is turned along with the shapes
+func (p *Picture) TranslateRotate(x, y, degrees float64) {
+	p.scope(fmt.Sprintf("shift={(%s,%s)}, rotate=%s, transform shape",
+		num(x), num(y), num(-degrees)))
+}
+
+func (p *Picture) EndGroup() {
+	p.indent--
+	p.printf(`\end{scope}`)
+}
+
+// Title is kept as a comment, tikz has nowhere to show it
+func (p *Picture) Title(text string) {
+	p.printf("%% %s", strings.Replace(text, "\n", " ", -1))
+}
+
+func (p *Picture) Line(x1, y1, x2, y2 float64, style visual.Style) {
+	visual.DrawLine(p, x1, y1, x2, y2, style)
+}
+
+func (p *Picture) Polyline(xs, ys []float64, style visual.Style) {
+	points := make([]string, len(xs))
+	for i := range xs {
+		points[i] = point(visual.Point{X: xs[i], Y: ys[i]})
+	}
+	p.draw(strings.Join(points, " -- "), style)
+}
+
+func (p *Picture) Path(path *visual.Path, style visual.Style) {
+	var ops []string
+	for _, seg := range path.ToCubics().Segments() {
+		switch seg.Op {
+		case visual.OpMove:
+			ops = append(ops, point(seg.Points[0]))
+		case visual.OpLine:
+			ops = append(ops, "-- "+point(seg.Points[0]))
+		case visual.OpCubic:
+			ops = append(ops, fmt.Sprintf(".. controls %s and %s .. %s",
+				point(seg.Points[0]), point(seg.Points[1]), point(seg.Points[2])))
+		case visual.OpClose:
+			ops = append(ops, "-- cycle")
+		}
+	}
+	p.draw(strings.Join(ops, " "), style)
+}
+
+func (p *Picture) Circle(cx, cy, r float64, style visual.Style) {
+	p.draw(fmt.Sprintf("(%s,%s) circle[radius=%s]", num(cx), num(cy), num(r)), style)
+}
+
+func (p *Picture) Rect(x, y, width, height float64, style visual.Style) {
+	p.draw(fmt.Sprintf("(%s,%s) rectangle (%s,%s)",
+		num(x), num(y), num(x+width), num(y+height)), style)
+}
+
+// draw paints the tikz `path` with the fill and stroke of `style`
+func (p *Picture) draw(path string, style visual.Style) {
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	var options []string
+	if c, ok := p.colour(fill); ok {
+		options = append(options, "fill="+c.name)
+		if a := alpha(c.Colour, style.FillOpacity); a < 1 {
+			options = append(options, "fill opacity="+num(a))
+		}
+	}
+	width := 1.0
+	if style.StrokeWidth != nil {
+		width = *style.StrokeWidth
+	}
+	if c, ok := p.colour(style.Stroke); ok && width > 0 {
+		options = append(options, "draw="+c.name, "line width="+num(width))
+		if a := alpha(c.Colour, style.StrokeOpacity); a < 1 {
+			options = append(options, "draw opacity="+num(a))
+		}
+		switch style.StrokeLineCap {
+		case visual.CapStyleRound:
+			options = append(options, "line cap=round")
+		case visual.CapStyleSquare:
+			options = append(options, "line cap=rect")
+		}
+	}
+	if len(options) == 0 {
+		return
+	}
+	p.printf(`\path[%s] %s;`, strings.Join(options, ", "), path)
+}
+
+// Text draws `text` as a node in the document font closest to the
+// style's family
+func (p *Picture) Text(x, y float64, text string, style visual.Style) {
+	fill := style.Fill
+	if fill == "" {
+		fill = "black"
+	}
+	size := 16
+	if style.FontSize != nil {
+		size = *style.FontSize
+	}
+	if size <= 0 {
+		return
+	}
+	c, ok := p.colour(fill)
+	if !ok {
+		return
+	}
+	vertical := "base"
+	switch style.DominantBaseline {
+	case "central", "middle":
+		vertical = "mid"
+	case "hanging", "text-before-edge":
+		vertical = "north"
+	}
+	anchor := vertical + " west"
+	switch style.TextAnchor {
+	case "middle":
+		anchor = vertical
+	case "end":
+		anchor = vertical + " east"
+	}
+	font := fontCommands[measure.Lookup(style.FontFamily).Name]
+	if w, err := strconv.Atoi(style.FontWeight); style.FontWeight == "bold" ||
+		(err == nil && w >= 600) {
+		font += `\bfseries`
+	}
+	font += fmt.Sprintf(`\fontsize{%d}{%s}\selectfont`, size, num(float64(size)*1.2))
+	options := []string{"anchor=" + anchor, "inner sep=0", "text=" + c.name,
+		"font=" + font}
+	if a := alpha(c.Colour, style.FillOpacity); a < 1 {
+		options = append(options, "text opacity="+num(a))
+	}
+	if !p.RawText {
+		text = specials.Replace(text)
+	}
+	p.printf(`\node[%s] at (%s,%s) {%s};`, strings.Join(options, ", "),
+		num(x), num(y), text)
+}
+
+type namedColour struct {
+	visual.Colour
+	name string
+}
+
+// colour parses the css colour `s`, defining it with xcolor the first
+// time it is used
+func (p *Picture) colour(s string) (namedColour, bool) {
+	if s == "" || s == "none" {
+		return namedColour{}, false
+	}
+	c, err := visual.ParseColour(s)
+	if err != nil || c.A == 0 {
+		return namedColour{}, false
+	}
+	r, g, b, _ := c.RGBA8()
+	hex := fmt.Sprintf("%02X%02X%02X", r, g, b)
+	for i, defined := range p.colours {
+		if defined == hex {
+			return namedColour{c, colourName(i)}, true
+		}
+	}
+	p.colours = append(p.colours, hex)
+	return namedColour{c, colourName(len(p.colours) - 1)}, true
+}
+
+// colourName names the `i`th defined colour. latex names can't hold
+// digits without care, so it is written in letters
+func colourName(i int) string {
+	name := ""
+	for {
+		name = string(rune('A'+i%26)) + name
+		i /= 26
+		if i == 0 {
+			break
+		}
+		i--
+	}
+	return "vis" + name
+}
+
+// alpha combines the alpha of a colour with an optional opacity
+func alpha(c visual.Colour, opacity *float64) float64 {
+	a := c.A
+	if opacity != nil {
+		a *= math.Max(0, math.Min(1, *opacity))
+	}
+	return a
+}
+
+func point(pt visual.Point) string {
+	return "(" + num(pt.X) + "," + num(pt.Y) + ")"
+}
+
+// num formats a number with up to three decimals
+func num(v float64) string {
+	return visual.FormatFloat(v, 3)
+}
@@ -0,0 +1,199 @@
+package tikz
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/diff"
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/clock"
+	"github.com/osraige/visualisations/gauge"
+	"github.com/osraige/visualisations/timeline"
+	"github.com/osraige/visualisations/visualtest"
+)
+
+func TestShapes(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, visualtest.Drawing{Width: 100, Height: 50, Calls: func(c visual.Canvas) {
+		c.TranslateRotate(10, 20, 90)
+		c.Line(0, 0, 10, 0, visual.Style{
+			Stroke:        "red",
+			StrokeWidth:   visual.Float(2),
+			StrokeLineCap: visual.CapStyleRound,
+			StrokeOpacity: visual.Float(0.5),
+		})
+		c.EndGroup()
+		c.Group("hands")
+		c.Title("a & b")
+		c.Rect(0, 0, 5, 5, visual.Style{Fill: "#0000ff", Stroke: "none"})
+		c.Path(visual.NewPath().MoveTo(0, 0).QuadTo(3, 0, 3, 3).Close(),
+			visual.Style{Fill: "none", Stroke: "red"})
+		c.EndGroup()
+		c.Text(50, 25, "50% of $x_1", visual.Style{
+			FontFamily:       "Courier",
+			FontSize:         visual.Int(10),
+			FontWeight:       "bold",
+			TextAnchor:       "middle",
+			DominantBaseline: "central",
+		})
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `\definecolor{visA}{HTML}{FF0000}
+\definecolor{visB}{HTML}{0000FF}
+\definecolor{visC}{HTML}{000000}
+\begin{tikzpicture}[x=1pt, y=-1pt, miter limit=4]
+  \useasboundingbox (0,0) rectangle (100,50);
+  \begin{scope}[shift={(10,20)}, rotate=-90, transform shape]
+    \path[draw=visA, line width=2, draw opacity=0.5, line cap=round] (0,0) -- (10,0);
+  \end{scope}
+  % hands
+  \begin{scope}
+    % a & b
+    \path[fill=visB] (0,0) rectangle (5,5);
+    \path[draw=visA, line width=1] (0,0) .. controls (2,0) and (3,1) .. (3,3) -- cycle;
+  \end{scope}
+  \node[anchor=mid, inner sep=0, text=visC, font=\ttfamily\bfseries\fontsize{10}{12}\selectfont] at (50,25) {50\% of \$x\_1};
+\end{tikzpicture}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected picture:\n%s", diff.Diff(want, got))
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, testcase := range []struct {
+		name    string
+		raw     bool
+		text    string
+		style   visual.Style
+		want    string
+		opacity bool
+	}{
+		{
+			name: "escaped",
+			text: `{a}^~\`,
+			want: `anchor=base west, inner sep=0, text=visA, font=\sffamily\fontsize{16}{19.2}\selectfont] at (1,2) {\{a\}\textasciicircum{}\textasciitilde{}\textbackslash{}};`,
+		},
+		{
+			name:  "raw",
+			raw:   true,
+			text:  `$\alpha$`,
+			style: visual.Style{FontFamily: "Georgia", TextAnchor: "end", DominantBaseline: "hanging"},
+			want:  `anchor=north east, inner sep=0, text=visA, font=\rmfamily\fontsize{16}{19.2}\selectfont] at (1,2) {$\alpha$};`,
+		},
+		{
+			name:  "faded",
+			text:  "a",
+			style: visual.Style{Fill: "rgba(0,0,0,0.5)", FillOpacity: visual.Float(0.5)},
+			want:  `anchor=base west, inner sep=0, text=visA, font=\sffamily\fontsize{16}{19.2}\selectfont, text opacity=0.25] at (1,2) {a};`,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewPicture(&buf)
+			p.RawText = testcase.raw
+			err := visual.DrawTo(p, visualtest.Drawing{Width: 10, Height: 10, Calls: func(c visual.Canvas) {
+				c.Text(1, 2, testcase.text, testcase.style)
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), testcase.want) {
+				t.Errorf("got:\n%s\nwant it to contain:\n%s", buf.String(), testcase.want)
+			}
+		})
+	}
+}
+
+func TestStandalone(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewPicture(&buf)
+	p.Standalone = true
+	err := visual.DrawTo(p, visualtest.Drawing{Width: 10, Height: 10, Calls: func(c visual.Canvas) {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `\documentclass[tikz]{standalone}
+\begin{document}
+\begin{tikzpicture}[x=1pt, y=-1pt, miter limit=4]
+  \useasboundingbox (0,0) rectangle (10,10);
+\end{tikzpicture}
+\end{document}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected document:\n%s", diff.Diff(want, got))
+	}
+}
+
+func TestColourName(t *testing.T) {
+	for i, want := range map[int]string{0: "visA", 25: "visZ", 26: "visAA", 27: "visAB", 702: "visAAA"} {
+		if got := colourName(i); got != want {
+			t.Errorf("colourName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestCharts(t *testing.T) {
+	for _, testcase := range []struct {
+		name  string
+		chart visual.Drawer
+		want  []string
+	}{
+		{
+			name: "gauge",
+			chart: gauge.GaugeOptions{
+				Size:             100,
+				Padding:          10,
+				GapRadians:       1,
+				LineWidth:        10,
+				Colour:           "green",
+				BackgroundColour: "#eeeeee",
+				FillProportion:   0.5,
+				Label:            "50%",
+				LabelColour:      "black",
+				LabelSize:        12,
+			},
+			want: []string{".. controls", "line width=10", "{50\\%}"},
+		},
+		{
+			name: "clock",
+			chart: clock.ClockOptions{
+				Size:        200,
+				Segments:    12,
+				DataHands:   []int{10, 50, 90},
+				DataAverage: []int{20, 40, 60},
+			},
+			want: []string{"rotate=", " -- "},
+		},
+		{
+			name: "timeline",
+			chart: timeline.TimelineOptions{
+				SegmentLength: 40,
+				LineWidth:     3,
+				GapWidth:      10,
+				GapHeight:     30,
+				PaddingX:      10,
+				PaddingY:      20,
+				LabelFontSize: 12,
+				Entries:       [][]string{{"alpha", "beta"}, {"beta"}},
+				ColumnLabels:  []string{"mon", "tue"},
+			},
+			want: []string{".. controls", "% alpha", "{mon}"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, testcase.chart); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range testcase.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("missing %q in:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -2,16 +2,16 @@ package timeline
 
 import (
 	"fmt"
-	"hash/fnv"
 	"io"
 	"sort"
 
-	svg "github.com/ajstarks/svgo/float"
 	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/format"
+	"github.com/osraige/visualisations/measure"
 )
 
 type TimelineOptions struct {
-	canvas  *svg.SVG
+	canvas  visual.Canvas
 	width   float64
 	height  float64
 	rows    float64
@@ -22,8 +22,15 @@ type TimelineOptions struct {
 	LineWidth float64
 	// Opacity of the lines when dropping out off the timeline
 	DropoutOpacity float64
-	LineCap        visual.CapStyle
-	baseLineStyle  string
+	// DropoutPattern strokes the lines dropping off the timeline with a
+	// pattern, such as a hatch, on canvases that support it. the pattern
+	// is drawn in the entry's colour unless it has a colour of its own
+	DropoutPattern *visual.Pattern
+	// LineFilter applies an effect such as a drop shadow to the lines of
+	// the entries, on canvases that support it
+	LineFilter    *visual.Filter
+	LineCap       visual.CapStyle
+	baseLineStyle visual.Style
 	// Radius of the dots at the begining of the segments
 	DotRadius float64
 	// Vertical distance between the lines
@@ -37,9 +44,11 @@ type TimelineOptions struct {
 	PaddingX float64
 	// Outer vertical padding
 	PaddingY float64
-	// Some function that when given the name of an entry will return a colour
+	// Some function that when given the name of an entry will return a colour,
+	// defaults to assigning colours from visual.Tableau10
 	GetColour func(string) string
-	// Some function that when given the name of an entry will return a colour
+	// Some function that when given the name of an entry will return a colour,
+	// defaults to the entry's colour
 	GetLabelColour func(string) string
 	// Some function that when given the name of an entry will return the label text
 	GetEntryLabel func(string) string
@@ -48,6 +57,12 @@ type TimelineOptions struct {
 	entries []entry
 	// Labels for the columns
 	ColumnLabels []string
+	// ColumnValues label the columns through ColumnFormat when there are
+	// no ColumnLabels, such as the start of each column as a duration
+	ColumnValues []float64
+	// ColumnFormat writes ColumnValues, defaults to six significant
+	// digits
+	ColumnFormat func(float64) string
 	// Colour for the column labels
 	ColumnLabelColour string
 	// Font size for the column labels
@@ -61,8 +76,18 @@ type TimelineOptions struct {
 	// Vertical distance from the entry start to the entry label
 	EntryLabelGap float64
 	// Text to display when no entries are provided
-	NoEntryText   string
-	baseTextStyle string
+	NoEntryText string
+	// Whether or not to grow the padding so that labels are not clipped
+	AutoPadding   bool
+	baseTextStyle visual.Style
+	// Theme provides defaults for any colours, fonts and widths left unset
+	Theme *visual.Theme
+	// DarkTheme recolours what was drawn in Theme's colours when the
+	// viewer prefers a dark colour scheme, on canvases that support it
+	DarkTheme *visual.Theme
+	// Accessibility sets the title and description read out by screen
+	// readers, the description defaults to the entries and columns
+	Accessibility visual.Accessibility
 }
 
 type entry struct {
@@ -91,10 +116,12 @@ type occurence struct {
 
 func (t *TimelineOptions) drawEntries() {
 	for _, e := range t.entries {
-		t.canvas.Group()
-		t.canvas.Title(e.name)
+		t.canvas.Group("")
+		if !t.Accessibility.Disabled {
+			t.canvas.Title(e.name)
+		}
 		t.drawEntry(e)
-		t.canvas.Gend()
+		t.canvas.EndGroup()
 	}
 }
 
@@ -104,14 +131,31 @@ func (t *TimelineOptions) drawEntry(e entry) {
 	entryColour := t.GetColour(e.name)
 	var prevLineX, prevLineY float64
 	var prevColumn float64
-	style := visual.ParseStyles(t.baseLineStyle,
-		visual.ParseStroke(entryColour))
-	fadedStyle := visual.ParseStyles(style,
-		visual.ParseStrokeOpacity(t.DropoutOpacity))
-	dotStyle := visual.ParseStyles(visual.ParseStroke(entryColour),
-		visual.ParseFill(entryColour))
-	textStyle := visual.ParseStyles(t.baseTextStyle,
-		visual.ParseFill(t.GetLabelColour(e.name)))
+	lineStyle := t.baseLineStyle.Override(visual.Style{
+		Stroke: entryColour,
+		Class:  "timeline-entry",
+	})
+	style := lineStyle
+	fadedStyle := lineStyle.Override(visual.Style{
+		StrokeOpacity: visual.Float(t.DropoutOpacity),
+		Class:         "timeline-dropout",
+	})
+	if t.DropoutPattern != nil {
+		pattern := *t.DropoutPattern
+		if pattern.Colour == "" {
+			pattern.Colour = entryColour
+		}
+		fadedStyle.StrokePaint = &visual.Paint{Pattern: &pattern}
+	}
+	dotStyle := visual.Style{
+		Stroke: entryColour,
+		Fill:   entryColour,
+		Class:  "timeline-dot",
+	}
+	textStyle := t.baseTextStyle.Override(visual.Style{
+		Fill:  t.GetLabelColour(e.name),
+		Class: "timeline-label",
+	})
 	for i, o := range e.occurences {
 		// Draw flat segment
 		startX := o.column*colWidth + t.PaddingX
@@ -155,12 +199,13 @@ func (t *TimelineOptions) drawEntry(e entry) {
 }
 
 func (t *TimelineOptions) connectorHelper(startX, startY,
-	endX, endY float64, style string) {
-	t.canvas.Bezier(startX, startY,
+	endX, endY float64, style visual.Style) {
+	curve := visual.NewPath().MoveTo(startX, startY).CubicTo(
 		startX+t.handleOffset, startY,
 		endX-t.handleOffset, endY,
-		endX, endY, style,
+		endX, endY,
 	)
+	t.canvas.Path(curve, style)
 }
 
 func (t *TimelineOptions) drawColumnLabels() {
@@ -169,23 +214,79 @@ func (t *TimelineOptions) drawColumnLabels() {
 	for i := 0.0; i < t.columns; i++ {
 		x := t.PaddingX + i*colWidth
 		t.canvas.Text(x, y, t.ColumnLabels[int(i)],
-			visual.ParseStyles(
-				visual.ParseFontFamily(t.LabelFont),
-				visual.ParseFontSize(t.LabelFontSize),
-				visual.ParseFill(t.ColumnLabelColour),
-			),
+			visual.Style{
+				FontFamily: t.LabelFont,
+				FontSize:   visual.Int(t.LabelFontSize),
+				Fill:       t.ColumnLabelColour,
+				Class:      "column-label",
+			},
 		)
 	}
 }
 
 func (t *TimelineOptions) drawNoEntryText() {
-	t.canvas.Text(t.width/2, t.height/2, t.NoEntryText, visual.ParseStyles(
-		visual.ParseFillOpacity(0.5),
-		visual.ParseFontFamily(t.LabelFont),
-		visual.ParseFontSize(t.LabelFontSize),
-		visual.ParseDominantBaseline("central"),
-		visual.ParseTextAnchor("middle"),
-	))
+	var colour string
+	if t.Theme != nil {
+		colour = t.Theme.TextColour.String()
+	}
+	t.canvas.Text(t.width/2, t.height/2, t.NoEntryText, visual.Style{
+		Fill:             colour,
+		FillOpacity:      visual.Float(0.5),
+		FontFamily:       t.LabelFont,
+		FontSize:         visual.Int(t.LabelFontSize),
+		DominantBaseline: "central",
+		TextAnchor:       "middle",
+		Class:            "timeline-empty",
+	})
+}
+
+func (t *TimelineOptions) applyTheme() {
+	if t.Theme == nil {
+		return
+	}
+	if t.GetColour == nil && len(t.Theme.Palette) > 0 {
+		t.GetColour = t.Theme.Palette.Assigner()
+	}
+	if t.LineWidth == 0 {
+		t.LineWidth = t.Theme.LineWidth
+	}
+	if t.LabelFont == "" {
+		t.LabelFont = t.Theme.FontFamily
+	}
+	if t.LabelFontSize == 0 {
+		t.LabelFontSize = t.Theme.FontSize
+	}
+	if t.ColumnLabelFontSize == 0 {
+		t.ColumnLabelFontSize = t.Theme.FontSize
+	}
+	if t.ColumnLabelColour == "" {
+		t.ColumnLabelColour = t.Theme.MutedTextColour.String()
+	}
+}
+
+// fitPadding grows the padding to fit the entry labels, which may hang
+// off the left edge when centred and over the top of the first row
+func (t *TimelineOptions) fitPadding() {
+	maxWidth := 0.0
+	for _, e := range t.entries {
+		w := measure.Width(t.GetEntryLabel(e.name), t.LabelFont, t.LabelFontSize)
+		if w > maxWidth {
+			maxWidth = w
+		}
+	}
+	if t.CentreText && t.PaddingX < maxWidth/2 {
+		t.PaddingX = maxWidth / 2
+	}
+	top := t.EntryLabelGap + measure.Height(t.LabelFont, t.LabelFontSize)/2
+	if t.PaddingY < top {
+		t.PaddingY = top
+	}
+}
+
+func (t *TimelineOptions) drawBackground() {
+	if t.Theme != nil && t.Theme.HasBackground() {
+		t.canvas.Rect(0, 0, t.width, t.height, t.Theme.BackgroundStyle())
+	}
 }
 
 func flattenEntries(entries [][]string) []entry {
@@ -214,27 +315,23 @@ func flattenEntries(entries [][]string) []entry {
 	return ret
 }
 
-func defaultGetColour(name string) string {
-	h := fnv.New32a()
-	_, _ = h.Write([]byte(name))
-	return fmt.Sprintf("#%06x", h.Sum32()%0xFFFFFF)
-}
-
 func defaultGetEntryLabel(name string) string {
 	return name
 }
 
+// GetTruncatedEntryLabel shortens entry names to `n` characters, as
+// measure.TruncateRunes does
 func GetTruncatedEntryLabel(n int) func(string) string {
 	return func(name string) string {
-		i := n
-		ret := name
-		if len(name) > i {
-			if i > 3 {
-				i -= 3
-			}
-			ret = name[0:i] + "..."
-		}
-		return ret
+		return measure.TruncateRunes(name, n)
+	}
+}
+
+// GetFittedEntryLabel shortens entry names to fit within `maxWidth`
+// pixels when drawn in `font` at `fontSize`
+func GetFittedEntryLabel(maxWidth float64, font string, fontSize int) func(string) string {
+	return func(name string) string {
+		return measure.Truncate(name, maxWidth, font, fontSize)
 	}
 }
 
@@ -251,59 +348,185 @@ func getMaxEntryColumnLength(entries [][]string) int {
 	return max
 }
 
-// Timeline gnerates a timeline from the given options
-func Timeline(out io.Writer, opts TimelineOptions) {
-	canvas := svg.New(out)
-	opts.columns = float64(len(opts.Entries))
-	opts.rows = float64(getMaxEntryColumnLength(opts.Entries))
-	opts.width = opts.columns*
-		(opts.SegmentLength+opts.GapWidth) -
-		opts.GapWidth + opts.PaddingX*2
-	opts.height = (opts.rows+1)*opts.GapHeight +
-		opts.PaddingY*2
-	opts.handleOffset = opts.GapWidth * opts.HandleGapRatio
-	if opts.GetColour == nil {
-		opts.GetColour = defaultGetColour
-	}
-	if opts.GetEntryLabel == nil {
-		opts.GetEntryLabel = defaultGetEntryLabel
-	}
-	if opts.GetLabelColour == nil {
-		opts.GetLabelColour = defaultGetColour
-	}
-	opts.baseTextStyle = visual.ParseStyles(
-		visual.ParseFontFamily(opts.LabelFont),
-		visual.ParseFontSize(opts.LabelFontSize),
-		visual.ParseDominantBaseline("central"),
-	)
-	if opts.CentreText {
-		opts.baseTextStyle = visual.ParseStyles(
-			opts.baseTextStyle,
-			visual.ParseTextAnchor("middle"),
-		)
+func init() {
+	visual.Register("timeline", func(decode visual.Decoder) (visual.Renderer, error) {
+		opts := TimelineOptions{}
+		err := decode(&opts)
+		return opts, err
+	})
+}
+
+// prepare fills in defaults and works out the layout of the timeline
+func (t *TimelineOptions) prepare() {
+	t.applyTheme()
+	t.formatColumns()
+	if t.GetColour == nil {
+		t.GetColour = visual.Tableau10.Assigner()
 	}
-	opts.baseLineStyle = visual.ParseStyles(
-		visual.ParseStrokeWidth(opts.LineWidth),
-		visual.ParseStrokeLineCap(opts.LineCap),
-		visual.ParseFill("none"),
-	)
-	opts.entries = flattenEntries(opts.Entries)
-	if len(opts.Entries) == 0 {
-		opts.height = 100
-		opts.width = 200
-		canvas.Start(opts.width, opts.height)
-		defer canvas.End()
-		opts.canvas = canvas
-		opts.canvas.Gid("root")
-		opts.drawNoEntryText()
-		opts.canvas.Gend()
+	if t.GetEntryLabel == nil {
+		t.GetEntryLabel = defaultGetEntryLabel
+	}
+	if t.GetLabelColour == nil {
+		t.GetLabelColour = t.GetColour
+	}
+	t.entries = flattenEntries(t.Entries)
+	if t.AutoPadding {
+		t.fitPadding()
+	}
+	t.columns = float64(len(t.Entries))
+	t.rows = float64(getMaxEntryColumnLength(t.Entries))
+	t.width = t.columns*
+		(t.SegmentLength+t.GapWidth) -
+		t.GapWidth + t.PaddingX*2
+	t.height = (t.rows+1)*t.GapHeight +
+		t.PaddingY*2
+	if len(t.Entries) == 0 {
+		t.height = 100
+		t.width = 200
+	}
+	t.handleOffset = t.GapWidth * t.HandleGapRatio
+	t.baseTextStyle = visual.Style{
+		FontFamily:       t.LabelFont,
+		FontSize:         visual.Int(t.LabelFontSize),
+		DominantBaseline: "central",
+	}
+	if t.CentreText {
+		t.baseTextStyle.TextAnchor = "middle"
+	}
+	t.baseLineStyle = visual.Style{
+		StrokeWidth:   visual.Float(t.LineWidth),
+		StrokeLineCap: t.LineCap,
+		Fill:          "none",
+		Filter:        t.LineFilter,
+	}
+}
+
+// Validate reports every option that would stop the timeline from being
+// drawn correctly
+func (t TimelineOptions) Validate() error {
+	t.formatColumns()
+	v := &visual.ValidationError{}
+	v.NonNegative("SegmentLength", t.SegmentLength)
+	v.NonNegative("LineWidth", t.LineWidth)
+	v.Between("DropoutOpacity", t.DropoutOpacity, 0, 1)
+	if t.DropoutPattern != nil {
+		// the colour defaults to each entry's
+		pattern := *t.DropoutPattern
+		if pattern.Colour == "" {
+			pattern.Colour = "black"
+		}
+		v.Nest("DropoutPattern", pattern.Validate())
+	}
+	if t.LineFilter != nil {
+		v.Nest("LineFilter", t.LineFilter.Validate())
+	}
+	switch t.LineCap {
+	case "", visual.CapStyleButt, visual.CapStyleRound, visual.CapStyleSquare:
+	default:
+		v.Add("LineCap", t.LineCap, "must be butt, round or square")
+	}
+	v.NonNegative("DotRadius", t.DotRadius)
+	v.NonNegative("GapHeight", t.GapHeight)
+	v.NonNegative("GapWidth", t.GapWidth)
+	v.NonNegative("HandleGapRatio", t.HandleGapRatio)
+	v.NonNegative("PaddingX", t.PaddingX)
+	v.NonNegative("PaddingY", t.PaddingY)
+	v.NonNegative("ColumnLabelFontSize", float64(t.ColumnLabelFontSize))
+	v.NonNegative("LabelFontSize", float64(t.LabelFontSize))
+	v.Check(len(t.ColumnLabels) >= len(t.Entries), "ColumnLabels",
+		len(t.ColumnLabels), fmt.Sprintf("must have a label for each of the %d columns",
+			len(t.Entries)))
+	return v.Err()
+}
+
+// formatColumns sets ColumnLabels from ColumnValues when there are none
+func (t *TimelineOptions) formatColumns() {
+	if len(t.ColumnLabels) > 0 || len(t.ColumnValues) == 0 {
 		return
 	}
-	canvas.Start(opts.width, opts.height)
-	defer canvas.End()
-	opts.canvas = canvas
-	opts.canvas.Gid("root")
-	opts.drawEntries()
-	opts.drawColumnLabels()
-	opts.canvas.Gend()
+	columnFormat := t.ColumnFormat
+	if columnFormat == nil {
+		columnFormat = format.Significant(6)
+	}
+	t.ColumnLabels = make([]string, len(t.ColumnValues))
+	for i, v := range t.ColumnValues {
+		t.ColumnLabels[i] = columnFormat(v)
+	}
+}
+
+// Render draws the timeline as an svg
+func (t TimelineOptions) Render(out io.Writer) error {
+	return Timeline(out, t)
+}
+
+func (t TimelineOptions) PreferredSize() (float64, float64) {
+	t.prepare()
+	return t.width, t.height
+}
+
+// Describe summarises the timeline for screen readers, listing its
+// entries and the span of its columns
+func (t TimelineOptions) Describe() (title, desc string) {
+	if len(t.Entries) == 0 {
+		desc = t.NoEntryText
+		if desc == "" {
+			desc = "Empty timeline"
+		}
+		return t.Accessibility.Describe("Timeline", desc)
+	}
+	t.formatColumns()
+	var names []string
+	for _, e := range flattenEntries(t.Entries) {
+		names = append(names, e.name)
+	}
+	desc = count(len(names), "entry", "entries") + " over " +
+		count(len(t.Entries), "column", "columns")
+	if n := len(t.Entries); len(t.ColumnLabels) >= n {
+		desc += fmt.Sprintf(" from %s to %s", t.ColumnLabels[0], t.ColumnLabels[n-1])
+	}
+	desc += ": " + visual.List(names)
+	return t.Accessibility.Describe("Timeline", desc)
+}
+
+// DarkColours maps the colours of Theme to those of DarkTheme. entries
+// take their colours from the palette, so they follow the dark palette
+func (t TimelineOptions) DarkColours() visual.ColourMap {
+	if t.Theme == nil || t.DarkTheme == nil {
+		return nil
+	}
+	colours := t.Theme.DarkColours(*t.DarkTheme)
+	palette := t.Theme.DarkPalette(*t.DarkTheme, "timeline-entry", "timeline-dropout", "timeline-dot")
+	for light, dark := range palette {
+		colours[light] = dark
+	}
+	return colours
+}
+
+func count(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// Draw draws the timeline onto `c`
+func (t TimelineOptions) Draw(c visual.Canvas) {
+	t.prepare()
+	t.canvas = c
+	c.Group("root")
+	defer c.EndGroup()
+	t.drawBackground()
+	if len(t.Entries) == 0 {
+		t.drawNoEntryText()
+		return
+	}
+	t.drawEntries()
+	t.drawColumnLabels()
+}
+
+// Timeline gnerates a timeline from the given options. nothing is
+// written if the options are invalid, otherwise any error from `out` is
+// returned
+func Timeline(out io.Writer, opts TimelineOptions) error {
+	return visual.DrawTo(visual.NewSVG(out), opts)
 }
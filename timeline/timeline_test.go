@@ -1,6 +1,7 @@
 package timeline
 
 import (
+	"errors"
 	"flag"
 	"os"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/kylelemons/godebug/diff"
 	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/format"
 	"github.com/osraige/visualisations/visualtest"
 )
 
@@ -92,11 +94,80 @@ func TestGenerate(t *testing.T) {
 				ColumnLabels: []string{"a", "b", "c", "d", "e", "f", "g"},
 				NoEntryText:  "No tags found in the last 7 days :(",
 			},
+		}, {
+			golden: "dark-theme",
+			timelineOptions: TimelineOptions{
+				SegmentLength:  40,
+				DropoutOpacity: 0.25,
+				DotRadius:      3,
+				GapHeight:      30,
+				GapWidth:       60,
+				HandleGapRatio: 0.3,
+				PaddingX:       40,
+				PaddingY:       20,
+				LineCap:        visual.CapStyleRound,
+				CentreText:     true,
+				EntryLabelGap:  15,
+				Entries: [][]string{
+					{"1", "2", "3"},
+					{"2", "1", "4"},
+					{"4", "1", "3"},
+				},
+				ColumnLabels: []string{"a", "b", "c"},
+				Theme:        &visual.DarkTheme,
+			},
+		}, {
+			golden: "hatched-dropouts",
+			timelineOptions: TimelineOptions{
+				SegmentLength:  40,
+				DropoutOpacity: 0.25,
+				DropoutPattern: &visual.Pattern{Kind: visual.PatternStripes, Spacing: 4, Angle: 45},
+				DotRadius:      3,
+				GapHeight:      30,
+				GapWidth:       60,
+				HandleGapRatio: 0.3,
+				PaddingX:       40,
+				PaddingY:       20,
+				Entries: [][]string{
+					{"1", "2"},
+					{"2"},
+					{"1", "2"},
+				},
+				ColumnLabels: []string{"a", "b", "c"},
+				Theme:        &visual.LightTheme,
+			},
+		}, {
+			golden: "auto-padding",
+			timelineOptions: TimelineOptions{
+				SegmentLength:       40,
+				LineWidth:           3,
+				DropoutOpacity:      0.25,
+				DotRadius:           3,
+				GapHeight:           30,
+				GapWidth:            60,
+				HandleGapRatio:      0.3,
+				LabelFontSize:       12,
+				LabelFont:           "sans-serif",
+				LineCap:             visual.CapStyleRound,
+				CentreText:          true,
+				EntryLabelGap:       15,
+				GetEntryLabel:       GetFittedEntryLabel(90, "sans-serif", 12),
+				ColumnLabelColour:   "grey",
+				ColumnLabelFontSize: 9,
+				AutoPadding:         true,
+				Entries: [][]string{
+					{"frontend", "a-very-long-tag-name-indeed"},
+					{"a-very-long-tag-name-indeed", "frontend"},
+				},
+				ColumnLabels: []string{"a", "b"},
+			},
 		},
 	} {
 		t.Run(testcase.golden, func(t *testing.T) {
 			builder := &strings.Builder{}
-			Timeline(builder, testcase.timelineOptions)
+			if err := Timeline(builder, testcase.timelineOptions); err != nil {
+				t.Fatal(err)
+			}
 			got := builder.String()
 			want := visualtest.GoldenValue(t, testcase.golden, got, *update)
 			if got != want {
@@ -105,3 +176,153 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTruncatedEntryLabel(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		n    int
+		want string
+	}{
+		{name: "short", n: 14, want: "short"},
+		{name: "exactly-fourteen", n: 16, want: "exactly-fourteen"},
+		{name: "much-too-long-to-fit", n: 10, want: "much-to..."},
+		{name: "ñandú-ñandú", n: 8, want: "ñandú..."},
+		{name: "abcdef", n: 2, want: "ab..."},
+	} {
+		if got := GetTruncatedEntryLabel(testcase.n)(testcase.name); got != testcase.want {
+			t.Errorf("%s: got %q, want %q", testcase.name, got, testcase.want)
+		}
+	}
+}
+
+func TestPreferredSize(t *testing.T) {
+	r, err := visual.NewFromJSON("timeline", []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := r.PreferredSize(); w != 200 || h != 100 {
+		t.Errorf("PreferredSize() = %v, %v, want 200, 100", w, h)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for _, testcase := range []struct {
+		name        string
+		options     TimelineOptions
+		title, desc string
+	}{
+		{
+			name:    "empty",
+			options: TimelineOptions{},
+			title:   "Timeline",
+			desc:    "Empty timeline",
+		},
+		{
+			name:    "no entry text",
+			options: TimelineOptions{NoEntryText: "Nothing yet"},
+			title:   "Timeline",
+			desc:    "Nothing yet",
+		},
+		{
+			name: "entries",
+			options: TimelineOptions{
+				Entries:      [][]string{{"b", "a"}, {"c"}, {"a"}},
+				ColumnLabels: []string{"mon", "tue", "wed"},
+			},
+			title: "Timeline",
+			desc:  "3 entries over 3 columns from mon to wed: a, b and c",
+		},
+		{
+			name: "column values",
+			options: TimelineOptions{
+				Entries:      [][]string{{"a"}, {"a"}},
+				ColumnValues: []float64{0, 5400},
+				ColumnFormat: format.Duration(2),
+			},
+			title: "Timeline",
+			desc:  "1 entry over 2 columns from 0s to 1h 30m: a",
+		},
+		{
+			name: "overridden",
+			options: TimelineOptions{
+				Entries:       [][]string{{"a"}},
+				Accessibility: visual.Accessibility{Title: "Tags"},
+			},
+			title: "Tags",
+			desc:  "1 entry over 1 column: a",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			title, desc := testcase.options.Describe()
+			if title != testcase.title || desc != testcase.desc {
+				t.Errorf("got %q, %q, want %q, %q", title, desc, testcase.title, testcase.desc)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, testcase := range []struct {
+		name   string
+		opts   TimelineOptions
+		fields []string
+	}{
+		{
+			name: "missing column labels",
+			opts: TimelineOptions{
+				Entries:      [][]string{{"a"}, {"a", "b"}, {"b"}},
+				ColumnLabels: []string{"first"},
+			},
+			fields: []string{"ColumnLabels"},
+		}, {
+			name: "out of range",
+			opts: TimelineOptions{
+				DropoutOpacity: 2,
+				PaddingX:       -5,
+				LineCap:        "pointy",
+			},
+			fields: []string{"DropoutOpacity", "LineCap", "PaddingX"},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			builder := &strings.Builder{}
+			err := Timeline(builder, testcase.opts)
+			verr, ok := err.(*visual.ValidationError)
+			if !ok {
+				t.Fatalf("got %v, want a *visual.ValidationError", err)
+			}
+			var fields []string
+			for _, fe := range verr.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if strings.Join(fields, " ") != strings.Join(testcase.fields, " ") {
+				t.Errorf("got invalid fields %v, want %v", fields, testcase.fields)
+			}
+			if builder.Len() != 0 {
+				t.Errorf("wrote %d bytes for invalid options", builder.Len())
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	dropped := errors.New("connection dropped")
+	w := &visualtest.FailingWriter{After: 100, Err: dropped}
+	err := Timeline(w, TimelineOptions{NoEntryText: "nothing yet"})
+	if err != dropped {
+		t.Errorf("got %v, want %v", err, dropped)
+	}
+}
+
+func TestDarkColours(t *testing.T) {
+	options := TimelineOptions{Theme: &visual.LightTheme, DarkTheme: &visual.DarkTheme}
+	colours := options.DarkColours()
+	// the first entry colour is also the theme's Primary
+	first := visual.Tableau10.At(0).String()
+	if got, want := colours[".timeline-entry "+first], visual.Set2.At(0).String(); got != want {
+		t.Errorf("entry colour %s mapped to %q, want %q", first, got, want)
+	}
+	if got, want := colours[first], visual.DarkTheme.Primary.String(); got != want {
+		t.Errorf("primary colour %s mapped to %q, want %q", first, got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package visualisations
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FieldError reports an option that has an invalid value
+type FieldError struct {
+	// Field is the name of the option, such as "Segments" or
+	// "[2].FillProportion" for options nested in a list
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == nil {
+		return e.Field + " " + e.Reason
+	}
+	return fmt.Sprintf("%s %s, got %v", e.Field, e.Reason, e.Value)
+}
+
+// ValidationError collects every invalid option of a visualisation, so
+// that they can all be fixed at once
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "visualisations: invalid options: " + strings.Join(msgs, "; ")
+}
+
+// Add records that `field` is invalid because of `reason`
+func (e *ValidationError) Add(field string, value interface{}, reason string) {
+	e.Errors = append(e.Errors, &FieldError{
+		Field:  field,
+		Value:  value,
+		Reason: reason,
+	})
+}
+
+// Check records that `field` is invalid when `ok` is false
+func (e *ValidationError) Check(ok bool, field string, value interface{}, reason string) {
+	if !ok {
+		e.Add(field, value, reason)
+	}
+}
+
+// NonNegative records an error if `value` is negative or not a number
+func (e *ValidationError) NonNegative(field string, value float64) {
+	e.Check(value >= 0 && !math.IsInf(value, 0), field, value,
+		"must be zero or more")
+}
+
+// Positive records an error if `value` is not greater than zero
+func (e *ValidationError) Positive(field string, value float64) {
+	e.Check(value > 0 && !math.IsInf(value, 0), field, value,
+		"must be more than zero")
+}
+
+// Between records an error if `value` is outside `min` to `max`
+// inclusive
+func (e *ValidationError) Between(field string, value, min, max float64) {
+	e.Check(value >= min && value <= max, field, value,
+		fmt.Sprintf("must be between %v and %v", min, max))
+}
+
+// Nest adds the errors of a nested validation with `prefix` put in
+// front of their fields. any other error is added as is under `prefix`
+func (e *ValidationError) Nest(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		e.Add(prefix, nil, err.Error())
+		return
+	}
+	for _, fe := range ve.Errors {
+		field := fe.Field
+		if !strings.HasPrefix(field, "[") {
+			field = "." + field
+		}
+		e.Add(prefix+field, fe.Value, fe.Reason)
+	}
+}
+
+// Err returns nil when nothing was recorded, otherwise the validation
+// error itself
+func (e *ValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
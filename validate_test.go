@@ -0,0 +1,27 @@
+package visualisations
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	v := &ValidationError{}
+	if v.Err() != nil {
+		t.Fatalf("Err() of an empty validation = %v, want nil", v.Err())
+	}
+	inner := &ValidationError{}
+	inner.Positive("Size", 0)
+	inner.Between("Opacity", math.NaN(), 0, 1)
+	v.Nest("[2]", inner.Err())
+	v.Nest("Axis", errors.New("has no scale"))
+	v.NonNegative("Width", 3)
+	want := "visualisations: invalid options: " +
+		"[2].Size must be more than zero, got 0; " +
+		"[2].Opacity must be between 0 and 1, got NaN; " +
+		"Axis has no scale"
+	if err := v.Err(); err == nil || err.Error() != want {
+		t.Errorf("got %v\nwant %s", err, want)
+	}
+}
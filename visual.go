@@ -9,6 +9,7 @@ package visualisations
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 )
 
@@ -26,6 +27,25 @@ func ScaleRange(n, rMin, rMax, tMin, tMax float64) float64 {
 	return ((n-rMin)/(rMax-rMin))*(tMax-tMin) + tMin
 }
 
+// FormatFloat writes `v` to at most `decimals` decimal places, without
+// trailing zeros or a negative zero, for outputs that write numbers as
+// text
+func FormatFloat(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// the Parse* functions are kept for compatibility and write the same
+// declarations they always have, even for empty values. new code should
+// build a Style and render it with Style.String, which leaves unset
+// fields out
+
 func ParseFill(colour string) string {
 	return fmt.Sprintf("fill:%s", colour)
 }
@@ -4,6 +4,8 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+
+	visual "github.com/osraige/visualisations"
 )
 
 func GoldenValue(t *testing.T, goldenFile string, actual string, update bool) string {
@@ -24,3 +26,40 @@ func GoldenValue(t *testing.T, goldenFile string, actual string, update bool) st
 	}
 	return string(content)
 }
+
+// FailingWriter accepts `After` bytes and then fails every write with
+// `Err`, to test how output is handled when a connection drops
+type FailingWriter struct {
+	After int
+	Err   error
+	n     int
+}
+
+func (f *FailingWriter) Write(p []byte) (int, error) {
+	if f.n+len(p) <= f.After {
+		f.n += len(p)
+		return len(p), nil
+	}
+	written := f.After - f.n
+	f.n = f.After
+	return written, f.Err
+}
+
+// Drawing is a visual.Drawer `Width` by `Height` that makes the canvas
+// calls in `Calls`, to test how a canvas draws individual shapes
+type Drawing struct {
+	Width, Height float64
+	Calls         func(c visual.Canvas)
+}
+
+func (d Drawing) Validate() error {
+	return nil
+}
+
+func (d Drawing) PreferredSize() (float64, float64) {
+	return d.Width, d.Height
+}
+
+func (d Drawing) Draw(c visual.Canvas) {
+	d.Calls(c)
+}
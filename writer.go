@@ -0,0 +1,41 @@
+package visualisations
+
+import "io"
+
+// ErrWriter wraps a writer and remembers the first error it returns.
+// svgo ignores write errors, so charts draw through an ErrWriter and
+// report its error once they are done. writes after a failure are
+// dropped rather than sending more of a broken document
+type ErrWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// NewErrWriter wraps `w`
+func NewErrWriter(w io.Writer) *ErrWriter {
+	return &ErrWriter{w: w}
+}
+
+func (e *ErrWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.n += int64(n)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	e.err = err
+	return n, err
+}
+
+// Err returns the first error from the wrapped writer
+func (e *ErrWriter) Err() error {
+	return e.err
+}
+
+// Written returns the number of bytes written successfully
+func (e *ErrWriter) Written() int64 {
+	return e.n
+}
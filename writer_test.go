@@ -0,0 +1,28 @@
+package visualisations_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	visual "github.com/osraige/visualisations"
+	"github.com/osraige/visualisations/visualtest"
+)
+
+func TestErrWriter(t *testing.T) {
+	dropped := errors.New("connection dropped")
+	w := visual.NewErrWriter(&visualtest.FailingWriter{After: 8, Err: dropped})
+	io.WriteString(w, "<svg>")
+	if w.Err() != nil {
+		t.Fatalf("Err() = %v after a successful write", w.Err())
+	}
+	io.WriteString(w, "<g>")
+	io.WriteString(w, "</g>")
+	io.WriteString(w, "</svg>")
+	if w.Err() != dropped {
+		t.Errorf("Err() = %v, want %v", w.Err(), dropped)
+	}
+	if w.Written() != 8 {
+		t.Errorf("Written() = %d, want 8", w.Written())
+	}
+}